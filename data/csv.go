@@ -0,0 +1,355 @@
+package data
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// utf8BOM UTF-8 字节顺序标记，Excel 依赖它识别 UTF-8 编码的 CSV
+const utf8BOM = "\ufeff"
+
+// NestedMode 嵌套值（map、数组）在 CSV 中的处理方式
+type NestedMode int
+
+const (
+	// NestedJSON 将嵌套值编码为 JSON 字符串写入一个单元格（默认）
+	NestedJSON NestedMode = iota
+	// NestedFlatten 将嵌套值展开为多列，列名形如 a.b[0].c，展开规则见 Flatten
+	NestedFlatten
+)
+
+// CSVOption CSV 导出的配置项
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	header     bool
+	columns    []string
+	rename     map[string]string
+	delimiter  rune
+	crlf       bool
+	bom        bool
+	nested     NestedMode
+	sampleSize int
+	location   *time.Location
+	flatten    []FlattenOption
+}
+
+// WithCSVHeader 输出表头行
+func WithCSVHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.header = true
+	}
+}
+
+// WithCSVColumns 指定输出的列及顺序，未指定时输出所有字段并按字段名排序
+func WithCSVColumns(columns ...string) CSVOption {
+	return func(o *csvOptions) {
+		o.columns = columns
+	}
+}
+
+// WithCSVColumnNames 设置表头中的列名，key 为字段名，未设置的列使用字段名
+func WithCSVColumnNames(names map[string]string) CSVOption {
+	return func(o *csvOptions) {
+		o.rename = names
+	}
+}
+
+// WithCSVDelimiter 设置分隔符，默认逗号
+func WithCSVDelimiter(delimiter rune) CSVOption {
+	return func(o *csvOptions) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithCSVCRLF 使用 \r\n 换行（RFC 4180），默认 \n
+func WithCSVCRLF() CSVOption {
+	return func(o *csvOptions) {
+		o.crlf = true
+	}
+}
+
+// WithCSVBOM 在开头写入 UTF-8 BOM，便于 Excel 正确识别中文
+func WithCSVBOM() CSVOption {
+	return func(o *csvOptions) {
+		o.bom = true
+	}
+}
+
+// WithCSVNested 设置嵌套值的处理方式，默认 NestedJSON
+func WithCSVNested(mode NestedMode) CSVOption {
+	return func(o *csvOptions) {
+		o.nested = mode
+	}
+}
+
+// WithCSVFlattenOptions 按给定的 Flatten 配置展开嵌套值，隐含 WithCSVNested(NestedFlatten)
+func WithCSVFlattenOptions(opts ...FlattenOption) CSVOption {
+	return func(o *csvOptions) {
+		o.nested = NestedFlatten
+		o.flatten = opts
+	}
+}
+
+func newCSVOptions(opts []CSVOption) *csvOptions {
+	o := &csvOptions{delimiter: ','}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// newWriter 按配置创建 csv.Writer
+func (o *csvOptions) newWriter(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	cw.Comma = o.delimiter
+	cw.UseCRLF = o.crlf
+	return cw
+}
+
+// headerRow 返回表头
+func (o *csvOptions) headerRow(columns []string) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c
+		if name, ok := o.rename[c]; ok {
+			row[i] = name
+		}
+	}
+	return row
+}
+
+// prepare 按嵌套处理方式整理一行
+func (o *csvOptions) prepare(obj map[string]interface{}) map[string]interface{} {
+	if o.nested != NestedFlatten {
+		return obj
+	}
+	return Flatten(obj, o.flatten...)
+}
+
+// WriteCSV 将对象数组按 RFC 4180 写入 w（含字段中的逗号、引号、换行的转义），每行以换行结尾
+func WriteCSV(w io.Writer, jsonArray []map[string]interface{}, opts ...CSVOption) error {
+	o := newCSVOptions(opts)
+	rows := make([]map[string]interface{}, len(jsonArray))
+	for i, obj := range jsonArray {
+		rows[i] = o.prepare(obj)
+	}
+
+	columns := o.columns
+	if len(columns) == 0 {
+		columns = collectColumns(rows)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	sw, err := o.newStreamWriter(w, columns)
+	if err != nil {
+		return err
+	}
+	for _, obj := range rows {
+		if err := sw.write(obj); err != nil {
+			return err
+		}
+	}
+	return sw.flush()
+}
+
+// collectColumns 收集所有字段名并排序
+func collectColumns(rows []map[string]interface{}) []string {
+	fieldSet := make(map[string]bool)
+	for _, obj := range rows {
+		for key := range obj {
+			fieldSet[key] = true
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields) // 排序确保输出顺序一致
+	return fields
+}
+
+// formatCSVValue 格式化单元格：nil 为空，浮点数不使用科学计数法，map、切片编码为 JSON
+func formatCSVValue(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case fmt.Stringer:
+		return x.String(), nil
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		b, err := json.Marshal(v)
+		return string(b), err
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
+
+// trimLastNewline 去掉末尾的一个换行
+func trimLastNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// DefaultCSVSampleSize StreamJSONToCSV 默认用于推断列的样本行数
+const DefaultCSVSampleSize = 100
+
+// WithCSVSampleSize 设置 StreamJSONToCSV 未指定列时用于推断列的前 n 行，之后出现的新字段会被忽略，默认 DefaultCSVSampleSize
+func WithCSVSampleSize(n int) CSVOption {
+	return func(o *csvOptions) {
+		o.sampleSize = n
+	}
+}
+
+// StreamJSONToCSV 从 r 逐个读取 JSON 数组中的对象并写入 CSV 到 w，内存占用只与样本行数和单个对象大小有关。
+// 列由 WithCSVColumns 指定，否则根据前 N 行（WithCSVSampleSize）中出现的字段排序得到；数字保持原样输出。
+// 与 WriteCSV 相同，每行以换行结尾
+func StreamJSONToCSV(w io.Writer, r io.Reader, opts ...CSVOption) error {
+	o := newCSVOptions(opts)
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("JSON解析失败: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("JSON解析失败: 需要对象数组")
+	}
+
+	next := func() (map[string]interface{}, bool, error) {
+		if !dec.More() {
+			return nil, false, nil
+		}
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err != nil {
+			return nil, false, fmt.Errorf("JSON解析失败: %w", err)
+		}
+		return o.prepare(obj), true, nil
+	}
+
+	// 未指定列时先读取样本推断列
+	columns := o.columns
+	var sample []map[string]interface{}
+	if len(columns) == 0 {
+		size := o.sampleSize
+		if size <= 0 {
+			size = DefaultCSVSampleSize
+		}
+		for len(sample) < size {
+			obj, ok, err := next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			sample = append(sample, obj)
+		}
+		columns = collectColumns(sample)
+	}
+
+	sw, err := o.newStreamWriter(w, columns)
+	if err != nil {
+		return err
+	}
+	for _, obj := range sample {
+		if err := sw.write(obj); err != nil {
+			return err
+		}
+	}
+	for {
+		obj, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if err := sw.write(obj); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("JSON解析失败: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("JSON解析失败: 数组之后存在多余内容")
+	}
+	return sw.flush()
+}
+
+// csvStreamWriter 按固定列逐行写入 CSV，定期刷新避免缓冲过大
+type csvStreamWriter struct {
+	cw      *csv.Writer
+	columns []string
+	record  []string
+	rows    int
+}
+
+// newStreamWriter 写入 BOM 和表头；columns 为空时不输出任何内容
+func (o *csvOptions) newStreamWriter(w io.Writer, columns []string) (*csvStreamWriter, error) {
+	sw := &csvStreamWriter{cw: o.newWriter(w), columns: columns, record: make([]string, len(columns))}
+	if len(columns) == 0 {
+		return sw, nil
+	}
+	if o.bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+	if o.header {
+		if err := sw.cw.Write(o.headerRow(columns)); err != nil {
+			return nil, err
+		}
+	}
+	return sw, nil
+}
+
+func (sw *csvStreamWriter) write(obj map[string]interface{}) error {
+	if len(sw.columns) == 0 {
+		return nil
+	}
+	for i, c := range sw.columns {
+		s, err := formatCSVValue(obj[c])
+		if err != nil {
+			return fmt.Errorf("字段 %s: %w", c, err)
+		}
+		sw.record[i] = s
+	}
+	if err := sw.cw.Write(sw.record); err != nil {
+		return err
+	}
+	sw.rows++
+	if sw.rows%1000 == 0 {
+		sw.cw.Flush()
+		return sw.cw.Error()
+	}
+	return nil
+}
+
+func (sw *csvStreamWriter) flush() error {
+	sw.cw.Flush()
+	return sw.cw.Error()
+}
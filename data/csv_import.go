@@ -0,0 +1,345 @@
+package data
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	gtime "github.com/hargeek/gopkg/time"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCSVTimeLayout 导入 CSV 时 time.Time 字段默认的时间格式
+const DefaultCSVTimeLayout = "2006-01-02 15:04:05"
+
+// CSVFieldError 导入 CSV 时某个单元格的错误，Line 和 Column 从 1 开始（Line 为该单元格在文件中开始的行号，含表头）
+type CSVFieldError struct {
+	Line   int
+	Column int
+	Header string
+	Value  string
+	Err    error
+}
+
+// Error 实现 error 接口
+func (e *CSVFieldError) Error() string {
+	return fmt.Sprintf("第 %d 行第 %d 列（%s）的值 %q 无效: %v", e.Line, e.Column, e.Header, e.Value, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *CSVFieldError) Unwrap() error { return e.Err }
+
+// WithCSVTimeLocation 设置导入时解析不带时区的时间所用的时区，默认 time.Local
+func WithCSVTimeLocation(loc *time.Location) CSVOption {
+	return func(o *csvOptions) {
+		o.location = loc
+	}
+}
+
+// csvReader 读取带表头的 CSV，去掉开头的 UTF-8 BOM
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVReader(r io.Reader, o *csvOptions) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = o.delimiter
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV 缺少表头")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取表头失败: %w", err)
+	}
+	header = append([]string(nil), header...)
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], utf8BOM)
+	}
+	seen := make(map[string]bool, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if seen[h] {
+			return nil, fmt.Errorf("表头第 %d 列 %q 重复", i+1, h)
+		}
+		seen[h] = true
+		header[i] = h
+	}
+	return &csvReader{r: cr, header: header}, nil
+}
+
+// read 读取下一行，结束时返回 io.EOF
+func (cr *csvReader) read() ([]string, error) {
+	return cr.r.Read()
+}
+
+// line 返回最近一次读取的行中第 column 列（从 0 开始）所在的行号，带引号的字段可能跨越多行
+func (cr *csvReader) line(column int) int {
+	line, _ := cr.r.FieldPos(column)
+	return line
+}
+
+// CSVToMaps 解析带表头的 CSV，每行转换为以表头为 key 的 map，值均为字符串，缺少的列为空字符串；
+// 分隔符可通过 WithCSVDelimiter 设置，开头的 UTF-8 BOM 会被忽略
+func CSVToMaps(r io.Reader, opts ...CSVOption) ([]map[string]any, error) {
+	cr, err := newCSVReader(r, newCSVOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]any
+	for {
+		record, err := cr.read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(cr.header))
+		for i, h := range cr.header {
+			v := ""
+			if i < len(record) {
+				v = record[i]
+			}
+			row[h] = v
+		}
+		rows = append(rows, row)
+	}
+}
+
+// CSVToStructs 解析带表头的 CSV 为结构体切片，遇到第一个错误时返回，列映射和类型转换规则见 CSVRows
+func CSVToStructs[T any](r io.Reader, opts ...CSVOption) ([]T, error) {
+	var rows []T
+	for row, err := range CSVRows[T](r, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// CSVRows 逐行解析带表头的 CSV 为结构体 T，适合大文件。
+// 列按字段的 csv tag（"-" 表示忽略）或字段名与表头匹配，表头中多余的列被忽略，无 tag 的匿名嵌入结构体字段会被展开；
+// 支持字符串、布尔、整数、浮点数、decimal.Decimal、time.Time（默认格式 DefaultCSVTimeLayout，可用 time_format tag 修改）、
+// time.Duration（支持 "1d" 等格式）、实现了 encoding.TextUnmarshaler 的类型及以上类型的指针；空单元格为零值，指针为 nil。
+// 某行转换失败时产出 *CSVFieldError 并继续下一行；CSV 格式错误或读取失败时产出错误后结束
+func CSVRows[T any](r io.Reader, opts ...CSVOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		o := newCSVOptions(opts)
+		typ := reflect.TypeOf(zero)
+		if typ == nil || typ.Kind() != reflect.Struct {
+			yield(zero, fmt.Errorf("类型参数必须是结构体: %v", typ))
+			return
+		}
+		cr, err := newCSVReader(r, o)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		plan := newCSVStructPlan(typ, cr.header)
+
+		for {
+			record, err := cr.read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			var row T
+			err = plan.decode(reflect.ValueOf(&row).Elem(), record, cr, o)
+			if err != nil {
+				row = zero
+			}
+			if !yield(row, err) {
+				return
+			}
+		}
+	}
+}
+
+// csvStructField 表头第 column 列对应的字段
+type csvStructField struct {
+	column     int
+	index      []int
+	timeFormat string
+}
+
+type csvStructPlan struct {
+	header []string
+	fields []csvStructField
+}
+
+// csvTaggedField 结构体中参与 CSV/XLSX 转换的字段
+type csvTaggedField struct {
+	name       string
+	index      []int
+	timeFormat string
+}
+
+// csvStructFields 按声明顺序返回结构体的字段，列名取 csv tag（"-" 表示忽略）或字段名，无 tag 的匿名嵌入结构体字段会被展开
+func csvStructFields(typ reflect.Type) []csvTaggedField {
+	var fields []csvTaggedField
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag, hasTag := f.Tag.Lookup("csv")
+			if tag == "-" {
+				continue
+			}
+			idx := append(append([]int(nil), index...), i)
+			if f.Anonymous && !hasTag && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, idx)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if name == "" {
+				name = f.Name
+			}
+			fields = append(fields, csvTaggedField{name: name, index: idx, timeFormat: f.Tag.Get("time_format")})
+		}
+	}
+	walk(typ, nil)
+	return fields
+}
+
+// newCSVStructPlan 按表头建立列与字段的映射
+func newCSVStructPlan(typ reflect.Type, header []string) *csvStructPlan {
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[h] = i
+	}
+	plan := &csvStructPlan{header: header}
+	for _, f := range csvStructFields(typ) {
+		if col, ok := columns[f.name]; ok {
+			plan.fields = append(plan.fields, csvStructField{column: col, index: f.index, timeFormat: f.timeFormat})
+		}
+	}
+	return plan
+}
+
+// decode 将一行写入 v，cr 用于定位出错单元格的行号
+func (p *csvStructPlan) decode(v reflect.Value, record []string, cr *csvReader, o *csvOptions) error {
+	for _, f := range p.fields {
+		if f.column >= len(record) {
+			continue
+		}
+		value := record[f.column]
+		if err := setCSVValue(v.FieldByIndex(f.index), value, f.timeFormat, o); err != nil {
+			return &CSVFieldError{Line: cr.line(f.column), Column: f.column + 1, Header: p.header[f.column], Value: value, Err: err}
+		}
+	}
+	return nil
+}
+
+var (
+	csvTimeType            = reflect.TypeOf(time.Time{})
+	csvDurationType        = reflect.TypeOf(time.Duration(0))
+	csvDecimalType         = reflect.TypeOf(decimal.Decimal{})
+	csvTextUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setCSVValue 将单元格的值转换后写入字段，字符串保持原样，其他类型忽略首尾空白
+func setCSVValue(field reflect.Value, raw, timeFormat string, o *csvOptions) error {
+	s := strings.TrimSpace(raw)
+	if field.Kind() == reflect.Ptr {
+		if s == "" {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		ptr := reflect.New(field.Type().Elem())
+		if err := setCSVValue(ptr.Elem(), raw, timeFormat, o); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	if s == "" {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	switch field.Type() {
+	case csvTimeType:
+		layout := timeFormat
+		if layout == "" {
+			layout = DefaultCSVTimeLayout
+		}
+		loc := o.location
+		if loc == nil {
+			loc = time.Local
+		}
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case csvDurationType:
+		d, err := gtime.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case csvDecimalType:
+		d, err := decimal.NewFromString(s)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(d))
+		return nil
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(csvTextUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("不支持的字段类型: %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type csvAudit struct {
+	ID uint `csv:"id"`
+}
+
+type csvPolicy struct {
+	csvAudit
+	PType     string           `csv:"ptype"`
+	Subject   string           `csv:"v0"`
+	Enabled   bool             `csv:"enabled"`
+	Weight    *int             `csv:"weight"`
+	Price     decimal.Decimal  `csv:"price"`
+	CreatedAt time.Time        `csv:"created_at"`
+	Date      *time.Time       `csv:"date" time_format:"2006-01-02"`
+	TTL       time.Duration    `csv:"ttl"`
+	Score     float64          // 无 tag 时按字段名匹配
+	Ignored   string           `csv:"-"`
+	Extra     *decimal.Decimal `csv:"extra"`
+}
+
+func TestCSVToMaps(t *testing.T) {
+	in := "\ufeffname,city\n张三,\"北京,朝阳\"\n李四\n"
+	rows, err := CSVToMaps(strings.NewReader(in))
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"name": "张三", "city": "北京,朝阳"},
+		{"name": "李四", "city": ""},
+	}, rows)
+
+	rows, err = CSVToMaps(strings.NewReader("a;b\n1;2\n"), WithCSVDelimiter(';'))
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"a": "1", "b": "2"}}, rows)
+
+	_, err = CSVToMaps(strings.NewReader(""))
+	assert.Error(t, err)
+	_, err = CSVToMaps(strings.NewReader("a,a\n1,2\n"))
+	assert.Error(t, err)
+	_, err = CSVToMaps(strings.NewReader("a,b\n\"1,2\n"))
+	assert.Error(t, err)
+}
+
+func TestCSVToStructs(t *testing.T) {
+	in := strings.Join([]string{
+		"id,ptype,v0,enabled,weight,price,created_at,date,ttl,Score,Ignored,unknown",
+		"1,p, alice ,true,3,12.50,2024-05-01 08:30:00,2024-05-02,1d,9.5,x,y",
+		"2,g,bob,false,,0.1,,,,,,",
+	}, "\n")
+	rows, err := CSVToStructs[csvPolicy](strings.NewReader(in), WithCSVTimeLocation(time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	first := rows[0]
+	assert.Equal(t, uint(1), first.ID)
+	assert.Equal(t, "p", first.PType)
+	assert.Equal(t, " alice ", first.Subject)
+	assert.True(t, first.Enabled)
+	assert.Equal(t, 3, *first.Weight)
+	assert.True(t, decimal.RequireFromString("12.5").Equal(first.Price))
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC), first.CreatedAt)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), *first.Date)
+	assert.Equal(t, 24*time.Hour, first.TTL)
+	assert.Equal(t, 9.5, first.Score)
+	assert.Empty(t, first.Ignored)
+	assert.Nil(t, first.Extra)
+
+	second := rows[1]
+	assert.Equal(t, uint(2), second.ID)
+	assert.Nil(t, second.Weight)
+	assert.Nil(t, second.Date)
+	assert.True(t, second.CreatedAt.IsZero())
+
+	_, err = CSVToStructs[int](strings.NewReader(in))
+	assert.Error(t, err)
+}
+
+func TestCSVRows(t *testing.T) {
+	in := "id,enabled,created_at\n1,true,2024-01-01 00:00:00\n2,maybe,2024-01-01 00:00:00\n3,false,2024/01/01\n4,true,\n"
+
+	var (
+		ids  []uint
+		errs []*CSVFieldError
+	)
+	for row, err := range CSVRows[csvPolicy](strings.NewReader(in)) {
+		var fe *CSVFieldError
+		if errors.As(err, &fe) {
+			errs = append(errs, fe)
+			continue
+		}
+		assert.NoError(t, err)
+		ids = append(ids, row.ID)
+	}
+	assert.Equal(t, []uint{1, 4}, ids)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, 2, errs[0].Column)
+	assert.Equal(t, "enabled", errs[0].Header)
+	assert.Equal(t, "maybe", errs[0].Value)
+	assert.ErrorIs(t, errs[0], strconv.ErrSyntax)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.Equal(t, 3, errs[1].Column)
+	assert.Contains(t, errs[1].Error(), "第 4 行第 3 列（created_at）")
+
+	// 带引号的字段跨越多行时按出错单元格定位
+	multiline := "ptype,enabled,weight\n\"multi\nline\",x,1\n\"a\",true,\"\n2x\"\n"
+	var fieldErrs []*CSVFieldError
+	for _, err := range CSVRows[csvPolicy](strings.NewReader(multiline)) {
+		var fe *CSVFieldError
+		if errors.As(err, &fe) {
+			fieldErrs = append(fieldErrs, fe)
+		}
+	}
+	if assert.Len(t, fieldErrs, 2) {
+		assert.Equal(t, 3, fieldErrs[0].Line)
+		assert.Equal(t, 2, fieldErrs[0].Column)
+		assert.Equal(t, 4, fieldErrs[1].Line)
+		assert.Equal(t, 3, fieldErrs[1].Column)
+	}
+
+	// 无效的时长
+	_, err := CSVToStructs[csvPolicy](strings.NewReader("ttl\nbad\n"))
+	var fe *CSVFieldError
+	assert.True(t, errors.As(err, &fe))
+	assert.Equal(t, "ttl", fe.Header)
+
+	// 提前结束迭代
+	count := 0
+	for range CSVRows[csvPolicy](strings.NewReader(in)) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	// 导出后再导入
+	exported := JsonArrayToCSV([]map[string]interface{}{{"ptype": "p", "v0": "a,b", "price": "1.5"}}, WithCSVHeader())
+	rows, err := CSVToStructs[csvPolicy](strings.NewReader(exported))
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b", rows[0].Subject)
+	assert.Equal(t, "1.5", rows[0].Price.String())
+}
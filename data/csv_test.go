@@ -0,0 +1,122 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonArrayToCSVOptions(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"PType": "p", "V0": "alice", "V1": "/data,1", "V2": `say "hi"`, "V3": "line1\nline2"},
+		{"PType": "g", "V0": "bob", "V1": 1000000.0, "V2": nil},
+	}
+
+	t.Run("TC01: RFC 4180 转义", func(t *testing.T) {
+		expected := "p,alice,\"/data,1\",\"say \"\"hi\"\"\",\"line1\nline2\"\ng,bob,1000000,,"
+		assert.Equal(t, expected, JsonArrayToCSV(rows))
+	})
+
+	t.Run("TC02: 表头、列顺序与重命名", func(t *testing.T) {
+		got := JsonArrayToCSV(rows,
+			WithCSVHeader(),
+			WithCSVColumns("V0", "PType", "Missing"),
+			WithCSVColumnNames(map[string]string{"V0": "Subject", "PType": "类型"}),
+		)
+		assert.Equal(t, "Subject,类型,Missing\nalice,p,\nbob,g,", got)
+
+		assert.Equal(t, "a,b", JsonArrayToCSV(nil, WithCSVHeader(), WithCSVColumns("a", "b")))
+		assert.Equal(t, "", JsonArrayToCSV(nil, WithCSVHeader()))
+	})
+
+	t.Run("TC03: 分隔符、换行与 BOM", func(t *testing.T) {
+		in := []map[string]interface{}{{"a": "1;2", "b": "x"}, {"a": "3", "b": "y"}}
+		assert.Equal(t, "\ufeffa;b\r\n\"1;2\";x\r\n3;y", JsonArrayToCSV(in, WithCSVHeader(), WithCSVDelimiter(';'), WithCSVCRLF(), WithCSVBOM()))
+		assert.Equal(t, "", JsonArrayToCSV(in, WithCSVDelimiter('"')))
+	})
+
+	t.Run("TC04: 嵌套值", func(t *testing.T) {
+		in := []map[string]interface{}{{
+			"id":   1,
+			"user": map[string]interface{}{"name": "alice", "tags": []interface{}{"a", "b"}},
+		}}
+		assert.Equal(t, `1,"{""name"":""alice"",""tags"":[""a"",""b""]}"`, JsonArrayToCSV(in))
+		assert.Equal(t, "id,user.name,user.tags[0],user.tags[1]\n1,alice,a,b", JsonArrayToCSV(in, WithCSVHeader(), WithCSVNested(NestedFlatten)))
+	})
+
+	t.Run("TC05: JSON 字符串与写入", func(t *testing.T) {
+		got, err := JsonStringToCSV(`[{"id": 12345678901234567890, "price": 1e3}]`, WithCSVHeader())
+		assert.NoError(t, err)
+		assert.Equal(t, "id,price\n12345678901234567890,1e3", got)
+
+		_, err = JsonStringToCSV(`[] []`)
+		assert.Error(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, WriteCSV(&buf, []map[string]interface{}{{"a": 1}}, WithCSVHeader()))
+		assert.Equal(t, "a\n1\n", buf.String())
+	})
+}
+
+func TestStreamJSONToCSV(t *testing.T) {
+	t.Run("TC01: 样本推断列", func(t *testing.T) {
+		in := `[{"b": 2, "a": "x,y"}, {"a": "z", "c": {"d": 1}}, {"a": "w", "e": "ignored"}]`
+		var buf bytes.Buffer
+		assert.NoError(t, StreamJSONToCSV(&buf, strings.NewReader(in), WithCSVHeader(), WithCSVSampleSize(2)))
+		assert.Equal(t, "a,b,c\n\"x,y\",2,\nz,,\"{\"\"d\"\":1}\"\nw,,\n", buf.String())
+
+		buf.Reset()
+		assert.NoError(t, StreamJSONToCSV(&buf, strings.NewReader(in), WithCSVNested(NestedFlatten), WithCSVHeader()))
+		assert.Equal(t, "a,b,c.d,e\n\"x,y\",2,,\nz,,1,\nw,,,ignored\n", buf.String())
+	})
+
+	t.Run("TC02: 指定列与空数组", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, StreamJSONToCSV(&buf, strings.NewReader(`[{"a":1,"b":2}]`), WithCSVColumns("b")))
+		assert.Equal(t, "2\n", buf.String())
+
+		buf.Reset()
+		assert.NoError(t, StreamJSONToCSV(&buf, strings.NewReader(` [ ] `), WithCSVHeader()))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("TC03: 无效输入", func(t *testing.T) {
+		for _, in := range []string{``, `{"a":1}`, `[{"a":1}`, `[{"a":1},]`, `[1]`, `[{"a":1},{"b":2}] garbage`, `[{"a":1}] []`} {
+			assert.Error(t, StreamJSONToCSV(io.Discard, strings.NewReader(in)), in)
+		}
+	})
+
+	t.Run("TC04: 大数据量流式处理", func(t *testing.T) {
+		const rows = 20000
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = io.WriteString(pw, "[")
+			for i := 0; i < rows; i++ {
+				if i > 0 {
+					_, _ = io.WriteString(pw, ",")
+				}
+				_, _ = fmt.Fprintf(pw, `{"id":%d,"name":"user-%d"}`, i, i)
+			}
+			_, _ = io.WriteString(pw, "]")
+			_ = pw.Close()
+		}()
+
+		counter := &lineCounter{}
+		assert.NoError(t, StreamJSONToCSV(counter, pr, WithCSVHeader()))
+		assert.Equal(t, rows+1, counter.lines)
+	})
+}
+
+// lineCounter 只统计行数，不保存内容
+type lineCounter struct {
+	lines int
+}
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	c.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
@@ -0,0 +1,335 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxUnflattenIndex Unflatten 允许的最大数组下标，防止恶意输入分配过大的数组
+const maxUnflattenIndex = 10000
+
+// ArrayMode 数组在路径中的表示方式
+type ArrayMode int
+
+const (
+	// ArrayBrackets 数组下标写作 a[0]（默认）
+	ArrayBrackets ArrayMode = iota
+	// ArraySeparator 数组下标与 key 一样用分隔符连接，写作 a.0；Unflatten 时除第一段外纯数字的段视为数组下标
+	ArraySeparator
+	// ArrayKeep 不展开数组，数组整体作为值
+	ArrayKeep
+)
+
+// FlattenOption Flatten、Unflatten 的配置项
+type FlattenOption func(*flattenOptions)
+
+type flattenOptions struct {
+	separator string
+	arrays    ArrayMode
+	maxDepth  int
+}
+
+// WithFlattenSeparator 设置 key 之间的分隔符，默认 "."
+func WithFlattenSeparator(sep string) FlattenOption {
+	return func(o *flattenOptions) {
+		o.separator = sep
+	}
+}
+
+// WithFlattenArrays 设置数组的处理方式，默认 ArrayBrackets
+func WithFlattenArrays(mode ArrayMode) FlattenOption {
+	return func(o *flattenOptions) {
+		o.arrays = mode
+	}
+}
+
+// WithFlattenMaxDepth 设置最多展开的层数，超过的部分整体作为值，<=0 表示不限制；如 1 表示只展开一层，{"a":{"b":{"c":1}}} 得到 {"a.b":{"c":1}}
+func WithFlattenMaxDepth(depth int) FlattenOption {
+	return func(o *flattenOptions) {
+		o.maxDepth = depth
+	}
+}
+
+func newFlattenOptions(opts []FlattenOption) *flattenOptions {
+	o := &flattenOptions{separator: "."}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.separator == "" {
+		o.separator = "."
+	}
+	return o
+}
+
+// Flatten 将嵌套对象展开为单层 map，key 为路径，如 {"a":{"b":[{"c":1}]}} -> {"a.b[0].c":1}；
+// 支持 map[string]T 和切片等任意嵌套类型，空 map、空数组保留为值
+func Flatten(v map[string]any, opts ...FlattenOption) map[string]any {
+	o := newFlattenOptions(opts)
+	out := make(map[string]any, len(v))
+	for k, child := range v {
+		o.flatten(k, child, 1, out)
+	}
+	return out
+}
+
+// flatten 展开 v 到 out，depth 为 prefix 的段数，即展开 v 后所在的层数
+func (o *flattenOptions) flatten(prefix string, v any, depth int, out map[string]any) {
+	if v == nil || (o.maxDepth > 0 && depth > o.maxDepth) {
+		out[prefix] = v
+		return
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		if len(x) == 0 {
+			out[prefix] = v
+			return
+		}
+		for k, child := range x {
+			o.flatten(prefix+o.separator+k, child, depth+1, out)
+		}
+		return
+	case []any:
+		if len(x) == 0 || o.arrays == ArrayKeep {
+			out[prefix] = v
+			return
+		}
+		for i, child := range x {
+			o.flatten(o.indexKey(prefix, i), child, depth+1, out)
+		}
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.Len() == 0 {
+			out[prefix] = v
+			return
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			o.flatten(prefix+o.separator+iter.Key().String(), iter.Value().Interface(), depth+1, out)
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 || o.arrays == ArrayKeep || rv.Type().Elem().Kind() == reflect.Uint8 {
+			out[prefix] = v
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			o.flatten(o.indexKey(prefix, i), rv.Index(i).Interface(), depth+1, out)
+		}
+	default:
+		out[prefix] = v
+	}
+}
+
+func (o *flattenOptions) indexKey(prefix string, i int) string {
+	if o.arrays == ArraySeparator {
+		return prefix + o.separator + strconv.Itoa(i)
+	}
+	return prefix + "[" + strconv.Itoa(i) + "]"
+}
+
+// pathSegment 路径中的一段：map 的 key 或数组下标
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath 解析 Flatten 生成的路径
+func (o *flattenOptions) parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for _, part := range strings.Split(path, o.separator) {
+		name := part
+		var indexes string
+		if o.arrays == ArrayBrackets {
+			if i := strings.IndexByte(part, '['); i >= 0 && strings.HasSuffix(part, "]") {
+				name, indexes = part[:i], part[i:]
+			}
+		}
+		if name != "" || indexes == "" {
+			if o.arrays == ArraySeparator && len(segments) > 0 && isDigits(name) {
+				n, err := parseIndex(name)
+				if err != nil {
+					return nil, err
+				}
+				segments = append(segments, pathSegment{index: n, isIndex: true})
+			} else {
+				segments = append(segments, pathSegment{key: name})
+			}
+		}
+		for indexes != "" {
+			end := strings.IndexByte(indexes, ']')
+			if indexes[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("无效的数组下标: %q", path)
+			}
+			n, err := parseIndex(indexes[1:end])
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, pathSegment{index: n, isIndex: true})
+			indexes = indexes[end+1:]
+		}
+	}
+	if len(segments) == 0 || segments[0].isIndex {
+		return nil, fmt.Errorf("路径 %q 必须以 key 开头", path)
+	}
+	return segments, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseIndex(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的数组下标: %q", s)
+	}
+	if n > maxUnflattenIndex {
+		return 0, fmt.Errorf("数组下标 %d 超过上限 %d", n, maxUnflattenIndex)
+	}
+	return n, nil
+}
+
+// flatArray Unflatten 过程中的数组
+type flatArray map[int]any
+
+// Unflatten 将 Flatten 生成的单层 map 还原为嵌套对象，需使用与 Flatten 相同的分隔符和数组方式；
+// 数组中缺少的下标为 nil，同一路径既是值又是对象（如 "a" 与 "a.b"）时返回错误
+func Unflatten(m map[string]any, opts ...FlattenOption) (map[string]any, error) {
+	o := newFlattenOptions(opts)
+	root := make(map[string]any)
+
+	// 按 key 排序保证冲突时的错误稳定
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		segments, err := o.parsePath(k)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertPath(root, segments, m[k], k); err != nil {
+			return nil, err
+		}
+	}
+	return finalizeUnflatten(root).(map[string]any), nil
+}
+
+// insertPath 沿路径创建容器并写入值
+func insertPath(root map[string]any, segments []pathSegment, value any, path string) error {
+	var node any = root
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		var next any
+		if !last {
+			if segments[i+1].isIndex {
+				next = flatArray{}
+			} else {
+				next = map[string]any{}
+			}
+		}
+
+		var existing any
+		var ok bool
+		switch n := node.(type) {
+		case map[string]any:
+			if seg.isIndex {
+				return fmt.Errorf("路径 %q 冲突: 对象不能使用数组下标", path)
+			}
+			existing, ok = n[seg.key]
+		case flatArray:
+			if !seg.isIndex {
+				return fmt.Errorf("路径 %q 冲突: 数组只能使用下标", path)
+			}
+			existing, ok = n[seg.index]
+		}
+
+		if last {
+			if !ok {
+				setChild(node, seg, value)
+				return nil
+			}
+			// 空 map、空数组占位且已有嵌套值时忽略
+			if isEmptyContainer(value) && !isEmptyContainer(existing) {
+				return nil
+			}
+			return fmt.Errorf("路径 %q 冲突: 已存在相同或嵌套的值", path)
+		}
+
+		// 已有的空 map、空数组占位直接替换，避免修改调用方传入的值
+		if ok && !isEmptyContainer(existing) {
+			switch existing.(type) {
+			case map[string]any, flatArray:
+				if reflect.TypeOf(existing) != reflect.TypeOf(next) {
+					return fmt.Errorf("路径 %q 冲突: 对象与数组混用", path)
+				}
+				node = existing
+				continue
+			default:
+				return fmt.Errorf("路径 %q 冲突: 上级路径已有值", path)
+			}
+		}
+		setChild(node, seg, next)
+		node = next
+	}
+	return nil
+}
+
+func setChild(node any, seg pathSegment, value any) {
+	switch n := node.(type) {
+	case map[string]any:
+		n[seg.key] = value
+	case flatArray:
+		n[seg.index] = value
+	}
+}
+
+// isEmptyContainer 判断是否为 Flatten 保留的空 map 或空数组
+func isEmptyContainer(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// finalizeUnflatten 将 flatArray 转换为 []any
+func finalizeUnflatten(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, child := range x {
+			x[k] = finalizeUnflatten(child)
+		}
+		return x
+	case flatArray:
+		size := 0
+		for i := range x {
+			size = max(size, i+1)
+		}
+		arr := make([]any, size)
+		for i, child := range x {
+			arr[i] = finalizeUnflatten(child)
+		}
+		return arr
+	default:
+		return v
+	}
+}
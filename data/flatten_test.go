@@ -0,0 +1,109 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	in := map[string]any{
+		"id": 1,
+		"user": map[string]any{
+			"name":  "alice",
+			"tags":  []any{"a", map[string]any{"k": "v"}},
+			"attrs": map[string]string{"city": "北京"},
+			"empty": map[string]any{},
+		},
+		"list": []int{7, 8},
+		"none": nil,
+		"raw":  []byte("x"),
+	}
+
+	t.Run("TC01: 默认路径格式", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"id":              1,
+			"user.name":       "alice",
+			"user.tags[0]":    "a",
+			"user.tags[1].k":  "v",
+			"user.attrs.city": "北京",
+			"user.empty":      map[string]any{},
+			"list[0]":         7,
+			"list[1]":         8,
+			"none":            nil,
+			"raw":             []byte("x"),
+		}, Flatten(in))
+	})
+
+	t.Run("TC02: 分隔符与数组方式", func(t *testing.T) {
+		got := Flatten(map[string]any{"a": map[string]any{"b": []any{1, 2}}}, WithFlattenSeparator("/"), WithFlattenArrays(ArraySeparator))
+		assert.Equal(t, map[string]any{"a/b/0": 1, "a/b/1": 2}, got)
+
+		got = Flatten(map[string]any{"a": map[string]any{"b": []any{1, 2}}}, WithFlattenArrays(ArrayKeep))
+		assert.Equal(t, map[string]any{"a.b": []any{1, 2}}, got)
+	})
+
+	t.Run("TC03: 最大层数", func(t *testing.T) {
+		nested := map[string]any{"a": map[string]any{"b": map[string]any{"c": 1}}, "x": 2}
+		assert.Equal(t, map[string]any{"a.b": map[string]any{"c": 1}, "x": 2}, Flatten(nested, WithFlattenMaxDepth(1)))
+		assert.Equal(t, map[string]any{"a.b.c": 1, "x": 2}, Flatten(nested, WithFlattenMaxDepth(2)))
+		assert.Equal(t, map[string]any{"a[0][0]": []any{1}}, Flatten(map[string]any{"a": []any{[]any{[]any{1}}}}, WithFlattenMaxDepth(2)))
+		assert.Equal(t, map[string]any{"a.b.c": 1, "x": 2}, Flatten(nested, WithFlattenMaxDepth(0)))
+	})
+}
+
+func TestUnflatten(t *testing.T) {
+	t.Run("TC01: 往返转换", func(t *testing.T) {
+		var in map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(`{"id":1,"user":{"name":"alice","tags":["a",{"k":"v"}],"empty":{},"list":[]},"matrix":[[1,2],[3]]}`), &in))
+
+		for _, opts := range [][]FlattenOption{
+			nil,
+			{WithFlattenSeparator("__")},
+			{WithFlattenArrays(ArraySeparator)},
+			{WithFlattenArrays(ArrayKeep)},
+		} {
+			got, err := Unflatten(Flatten(in, opts...), opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, in, got)
+		}
+	})
+
+	t.Run("TC02: 稀疏数组与纯数字 key", func(t *testing.T) {
+		got, err := Unflatten(map[string]any{"a[2]": "x", "2024.total": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": []any{nil, nil, "x"}, "2024": map[string]any{"total": 1}}, got)
+
+		got, err = Unflatten(map[string]any{"2024.0": "x"}, WithFlattenArrays(ArraySeparator))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"2024": []any{"x"}}, got)
+	})
+
+	t.Run("TC03: 冲突与无效路径", func(t *testing.T) {
+		for _, in := range []map[string]any{
+			{"a": 1, "a.b": 2},
+			{"a.b": 1, "a[0]": 2},
+			{"a[x]": 1},
+			{"[0]": 1},
+			{"a[100000]": 1},
+		} {
+			_, err := Unflatten(in)
+			assert.Error(t, err, in)
+		}
+	})
+
+	t.Run("TC04: 不修改传入的空对象", func(t *testing.T) {
+		empty := map[string]any{}
+		got, err := Unflatten(map[string]any{"a": empty, "a.b": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": map[string]any{"b": 1}}, got)
+		assert.Empty(t, empty)
+	})
+}
+
+func TestCSVFlattenOptions(t *testing.T) {
+	in := []map[string]interface{}{{"id": 1, "user": map[string]interface{}{"name": "alice", "tags": []interface{}{"a", "b"}}}}
+	assert.Equal(t, "id,user_name,user_tags\n1,alice,\"[\"\"a\"\",\"\"b\"\"]\"",
+		JsonArrayToCSV(in, WithCSVHeader(), WithCSVFlattenOptions(WithFlattenSeparator("_"), WithFlattenArrays(ArrayKeep))))
+}
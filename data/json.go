@@ -1,55 +1,47 @@
 package data
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
+	"io"
 	"strings"
 )
 
 // JsonArrayToCSV 将JSON数组转换为CSV格式
-func JsonArrayToCSV(jsonArray []map[string]interface{}) string {
-	// 空数组返回空字符串
-	if len(jsonArray) == 0 {
+// 默认不输出表头，列按字段名排序，\n 换行且末尾不带换行；含逗号、引号、换行的值按 RFC 4180 加引号转义，
+// 嵌套的 map、数组编码为 JSON；可通过 CSVOption 输出表头、指定列、修改分隔符等。配置无效（如分隔符非法）时返回空字符串
+func JsonArrayToCSV(jsonArray []map[string]interface{}, opts ...CSVOption) string {
+	s, err := jsonArrayToCSV(jsonArray, opts)
+	if err != nil {
 		return ""
 	}
+	return s
+}
 
-	// 收集所有唯一的字段名并排序
-	fieldSet := make(map[string]bool)
-	for _, obj := range jsonArray {
-		for key := range obj {
-			fieldSet[key] = true
-		}
-	}
-
-	var fields []string
-	for field := range fieldSet {
-		fields = append(fields, field)
+// JsonStringToCSV 从JSON字符串解析并转换为CSV格式，数字保持原样输出
+func JsonStringToCSV(jsonArrayStr string, opts ...CSVOption) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(jsonArrayStr))
+	dec.UseNumber()
+	var jsonArray []map[string]interface{}
+	if err := dec.Decode(&jsonArray); err != nil {
+		return "", fmt.Errorf("JSON解析失败: %v", err)
 	}
-	sort.Strings(fields) // 排序确保输出顺序一致
-
-	// 构建CSV行
-	var csvLines []string
-	for _, obj := range jsonArray {
-		var values []string
-		for _, field := range fields {
-			if value, exists := obj[field]; exists && value != nil {
-				values = append(values, fmt.Sprintf("%v", value))
-			} else {
-				values = append(values, "") // 空值
-			}
-		}
-		csvLines = append(csvLines, strings.Join(values, ","))
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("JSON解析失败: 数组之后存在多余内容")
 	}
-
-	return strings.Join(csvLines, "\n")
+	return jsonArrayToCSV(jsonArray, opts)
 }
 
-// JsonStringToCSV 从JSON字符串解析并转换为CSV格式
-func JsonStringToCSV(jsonArrayStr string) (string, error) {
-	var jsonArray []map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonArrayStr), &jsonArray); err != nil {
-		return "", fmt.Errorf("JSON解析失败: %v", err)
+func jsonArrayToCSV(jsonArray []map[string]interface{}, opts []CSVOption) (string, error) {
+	// 空数组返回空字符串
+	if len(jsonArray) == 0 && len(newCSVOptions(opts).columns) == 0 {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, jsonArray, opts...); err != nil {
+		return "", err
 	}
-	return JsonArrayToCSV(jsonArray), nil
+	return trimLastNewline(buf.String()), nil
 }
@@ -0,0 +1,660 @@
+package data
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultXLSXSheetName JsonArrayToXLSX、StructsToXLSX 默认的工作表名
+	DefaultXLSXSheetName = "Sheet1"
+	// DefaultXLSXDateFormat 日期单元格默认的显示格式
+	DefaultXLSXDateFormat = "yyyy-mm-dd hh:mm:ss"
+
+	// xlsxMaxRows、xlsxMaxColumns Excel 单个工作表的行数、列数上限
+	xlsxMaxRows    = 1048576
+	xlsxMaxColumns = 16384
+	// xlsxMaxSheetName 工作表名的最大长度
+	xlsxMaxSheetName = 31
+	// xlsxMaxExactNumber Excel 数字只有 15 位有效数字，绝对值不小于它的整数写为文本以免丢失精度
+	xlsxMaxExactNumber = 1e15
+
+	// 单元格样式，对应 styles.xml 中 cellXfs 的下标
+	xlsxStyleHeader = 1
+	xlsxStyleDate   = 2
+)
+
+// xlsxEpoch Excel 日期序列号的起点
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// XLSXOption XLSX 导出的配置项，可传给 NewXLSXWriter 作为所有工作表的默认配置，也可传给 AddSheet 只对该工作表生效
+type XLSXOption func(*xlsxOptions)
+
+type xlsxOptions struct {
+	sheetName  string
+	columns    []string
+	rename     map[string]string
+	widths     map[string]float64
+	freeze     bool
+	dateFormat string
+	nested     NestedMode
+	flatten    []FlattenOption
+}
+
+// WithXLSXSheetName 设置 JsonArrayToXLSX、StructsToXLSX 的工作表名，默认 DefaultXLSXSheetName
+func WithXLSXSheetName(name string) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.sheetName = name
+	}
+}
+
+// WithXLSXColumns 指定 JsonArrayToXLSX、StructsToXLSX 输出的列及顺序，未指定时 map 按字段名排序，结构体按字段声明顺序
+func WithXLSXColumns(columns ...string) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.columns = columns
+	}
+}
+
+// WithXLSXColumnNames 设置表头中的列名，key 为字段名，未设置的列使用字段名
+func WithXLSXColumnNames(names map[string]string) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.rename = names
+	}
+}
+
+// WithXLSXColumnWidths 设置列宽（字符数），key 为字段名；未设置的列按表头长度估算
+func WithXLSXColumnWidths(widths map[string]float64) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.widths = widths
+	}
+}
+
+// WithXLSXFreezeHeader 设置是否冻结表头行，默认冻结
+func WithXLSXFreezeHeader(freeze bool) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.freeze = freeze
+	}
+}
+
+// WithXLSXDateFormat 设置日期单元格的显示格式，默认 DefaultXLSXDateFormat；格式属于整个工作簿，只在 NewXLSXWriter 中生效
+func WithXLSXDateFormat(format string) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.dateFormat = format
+	}
+}
+
+// WithXLSXNested 设置 map 中嵌套值的处理方式，默认 NestedJSON
+func WithXLSXNested(mode NestedMode) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.nested = mode
+	}
+}
+
+// WithXLSXFlattenOptions 按给定的 Flatten 配置展开 map 中的嵌套值，隐含 WithXLSXNested(NestedFlatten)
+func WithXLSXFlattenOptions(opts ...FlattenOption) XLSXOption {
+	return func(o *xlsxOptions) {
+		o.nested = NestedFlatten
+		o.flatten = opts
+	}
+}
+
+func newXLSXOptions(base *xlsxOptions, opts []XLSXOption) *xlsxOptions {
+	o := &xlsxOptions{sheetName: DefaultXLSXSheetName, freeze: true, dateFormat: DefaultXLSXDateFormat}
+	if base != nil {
+		*o = *base
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// prepare 按嵌套处理方式整理一行
+func (o *xlsxOptions) prepare(obj map[string]interface{}) map[string]interface{} {
+	if o.nested != NestedFlatten {
+		return obj
+	}
+	return Flatten(obj, o.flatten...)
+}
+
+// XLSXWriter 以流式方式生成 XLSX 工作簿，行数据直接压缩写入 w，内存占用与行数无关。
+// 工作表依次写入：调用 AddSheet 后用 WriteRow、WriteMap、WriteStruct 写入行，再次调用 AddSheet 即结束上一个工作表；
+// 最后必须调用 Close 写入工作簿的其余部分。XLSXWriter 不是并发安全的
+type XLSXWriter struct {
+	zw     *zip.Writer
+	opts   *xlsxOptions
+	sheets []string
+	sheet  *xlsxSheet
+	closed bool
+}
+
+// xlsxSheet 正在写入的工作表
+type xlsxSheet struct {
+	bw      *bufio.Writer
+	opts    *xlsxOptions
+	columns []string
+	fields  []csvTaggedField
+	rows    int
+	buf     []byte
+}
+
+// NewXLSXWriter 创建写入 w 的 XLSXWriter，opts 作为所有工作表的默认配置
+func NewXLSXWriter(w io.Writer, opts ...XLSXOption) *XLSXWriter {
+	return &XLSXWriter{zw: zip.NewWriter(w), opts: newXLSXOptions(nil, opts)}
+}
+
+// AddSheet 结束当前工作表并开始一个新的工作表，columns 为表头（字段名，可通过 WithXLSXColumnNames 重命名），为空时不输出表头。
+// 工作表名不能为空、不超过 31 个字符、不含 []:*?/\ 且不能重复（不区分大小写）
+func (x *XLSXWriter) AddSheet(name string, columns []string, opts ...XLSXOption) error {
+	if x.closed {
+		return errors.New("XLSXWriter 已关闭")
+	}
+	if err := x.checkSheetName(name); err != nil {
+		return err
+	}
+	if len(columns) > xlsxMaxColumns {
+		return fmt.Errorf("列数 %d 超过上限 %d", len(columns), xlsxMaxColumns)
+	}
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	fw, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)+1))
+	if err != nil {
+		return err
+	}
+	x.sheets = append(x.sheets, name)
+	sheet := &xlsxSheet{bw: bufio.NewWriter(fw), opts: newXLSXOptions(x.opts, opts), columns: columns}
+	x.sheet = sheet
+	return sheet.begin(len(x.sheets) == 1)
+}
+
+// AddStructSheet 开始一个写入结构体 T 的工作表，列为 T 的字段（csv tag 或字段名，规则同 CSVRows），可用 WithXLSXColumns 指定列及顺序
+func AddStructSheet[T any](x *XLSXWriter, name string, opts ...XLSXOption) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("类型参数必须是结构体: %v", typ)
+	}
+	fields := csvStructFields(typ)
+	if o := newXLSXOptions(x.opts, opts); len(o.columns) > 0 {
+		byName := make(map[string]csvTaggedField, len(fields))
+		for _, f := range fields {
+			byName[f.name] = f
+		}
+		fields = fields[:0:0]
+		for _, c := range o.columns {
+			f, ok := byName[c]
+			if !ok {
+				return fmt.Errorf("结构体 %v 没有字段 %s", typ, c)
+			}
+			fields = append(fields, f)
+		}
+	}
+	columns := make([]string, len(fields))
+	if fields == nil {
+		fields = []csvTaggedField{}
+	}
+	for i, f := range fields {
+		columns[i] = f.name
+	}
+	if err := x.AddSheet(name, columns, opts...); err != nil {
+		return err
+	}
+	x.sheet.fields = fields
+	return nil
+}
+
+func (x *XLSXWriter) checkSheetName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > xlsxMaxSheetName {
+		return fmt.Errorf("工作表名 %q 长度必须在 1 到 %d 之间", name, xlsxMaxSheetName)
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) {
+		return fmt.Errorf("工作表名 %q 不能包含 []:*?/\\", name)
+	}
+	for _, s := range x.sheets {
+		if strings.EqualFold(s, name) {
+			return fmt.Errorf("工作表名 %q 重复", name)
+		}
+	}
+	return nil
+}
+
+// currentSheet 返回正在写入的工作表
+func (x *XLSXWriter) currentSheet() (*xlsxSheet, error) {
+	if x.closed {
+		return nil, errors.New("XLSXWriter 已关闭")
+	}
+	if x.sheet == nil {
+		return nil, errors.New("请先调用 AddSheet")
+	}
+	return x.sheet, nil
+}
+
+// WriteRow 按顺序写入一行。数字、decimal.Decimal 写为数值（超过 15 位的整数写为文本以免丢失精度），
+// time.Time 写为日期，布尔值写为逻辑值，nil 和零值时间为空单元格，实现了 fmt.Stringer 的类型写为文本，map、切片编码为 JSON
+func (x *XLSXWriter) WriteRow(values ...interface{}) error {
+	sheet, err := x.currentSheet()
+	if err != nil {
+		return err
+	}
+	return sheet.writeRow(values)
+}
+
+// WriteMap 按 AddSheet 的列写入一行，缺少的字段为空单元格
+func (x *XLSXWriter) WriteMap(obj map[string]interface{}) error {
+	sheet, err := x.currentSheet()
+	if err != nil {
+		return err
+	}
+	obj = sheet.opts.prepare(obj)
+	values := make([]interface{}, len(sheet.columns))
+	for i, c := range sheet.columns {
+		values[i] = obj[c]
+	}
+	return sheet.writeRow(values)
+}
+
+// WriteStruct 写入一行结构体（或其指针），当前工作表必须由 AddStructSheet 创建
+func (x *XLSXWriter) WriteStruct(v interface{}) error {
+	sheet, err := x.currentSheet()
+	if err != nil {
+		return err
+	}
+	if sheet.fields == nil {
+		return errors.New("当前工作表不是由 AddStructSheet 创建的")
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("需要结构体: %T", v)
+	}
+	values := make([]interface{}, len(sheet.fields))
+	for i, f := range sheet.fields {
+		field, err := rv.FieldByIndexErr(f.index)
+		if err != nil || (field.Kind() == reflect.Ptr && field.IsNil()) {
+			continue
+		}
+		values[i] = reflect.Indirect(field).Interface()
+	}
+	return sheet.writeRow(values)
+}
+
+// Close 结束当前工作表并写入工作簿、样式等其余部分，不会关闭底层的 io.Writer；未添加工作表时生成一个空的工作表
+func (x *XLSXWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	if len(x.sheets) == 0 {
+		if err := x.AddSheet(x.opts.sheetName, nil); err != nil {
+			return err
+		}
+	}
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+	x.closed = true
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", x.contentTypes()},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", x.workbook()},
+		{"xl/_rels/workbook.xml.rels", x.workbookRels()},
+		{"xl/styles.xml", x.styles()},
+	}
+	for _, p := range parts {
+		fw, err := x.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, p.content); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
+
+func (x *XLSXWriter) endSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	sheet := x.sheet
+	x.sheet = nil
+	if _, err := sheet.bw.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return sheet.bw.Flush()
+}
+
+// begin 写入工作表的视图、列宽和表头
+func (s *xlsxSheet) begin(selected bool) error {
+	b := s.buf[:0]
+	b = append(b, xml.Header...)
+	b = append(b, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheetViews><sheetView workbookViewId="0"`...)
+	if selected {
+		b = append(b, ` tabSelected="1"`...)
+	}
+	b = append(b, '>')
+	if s.opts.freeze && len(s.columns) > 0 {
+		b = append(b, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`...)
+	}
+	b = append(b, `</sheetView></sheetViews><sheetFormatPr defaultRowHeight="15"/>`...)
+	if len(s.columns) > 0 {
+		b = append(b, `<cols>`...)
+		for i, c := range s.columns {
+			width, ok := s.opts.widths[c]
+			if !ok {
+				width = xlsxAutoWidth(s.headerName(c))
+			}
+			b = fmt.Appendf(b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		b = append(b, `</cols>`...)
+	}
+	b = append(b, `<sheetData>`...)
+	s.buf = b
+	if _, err := s.bw.Write(b); err != nil {
+		return err
+	}
+	if len(s.columns) == 0 {
+		return nil
+	}
+	header := make([]interface{}, len(s.columns))
+	for i, c := range s.columns {
+		header[i] = s.headerName(c)
+	}
+	return s.writeStyledRow(header, xlsxStyleHeader)
+}
+
+func (s *xlsxSheet) headerName(column string) string {
+	if name, ok := s.opts.rename[column]; ok {
+		return name
+	}
+	return column
+}
+
+func (s *xlsxSheet) writeRow(values []interface{}) error {
+	return s.writeStyledRow(values, 0)
+}
+
+// writeStyledRow 写入一行，style 非 0 时应用于所有单元格
+func (s *xlsxSheet) writeStyledRow(values []interface{}, style int) error {
+	if s.rows >= xlsxMaxRows {
+		return fmt.Errorf("行数超过上限 %d", xlsxMaxRows)
+	}
+	if len(values) > xlsxMaxColumns {
+		return fmt.Errorf("列数 %d 超过上限 %d", len(values), xlsxMaxColumns)
+	}
+	s.rows++
+	b := fmt.Appendf(s.buf[:0], `<row r="%d">`, s.rows)
+	for i, v := range values {
+		var err error
+		b, err = appendXLSXCell(b, xlsxCellRef(i, s.rows), v, style)
+		if err != nil {
+			return fmt.Errorf("第 %d 行第 %d 列: %w", s.rows, i+1, err)
+		}
+	}
+	b = append(b, `</row>`...)
+	s.buf = b
+	_, err := s.bw.Write(b)
+	return err
+}
+
+// appendXLSXCell 按值的类型写入单元格
+func appendXLSXCell(b []byte, ref string, v interface{}, style int) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return b, nil
+	case string:
+		return appendXLSXString(b, ref, x, style), nil
+	case bool:
+		n := "0"
+		if x {
+			n = "1"
+		}
+		return appendXLSXValue(b, ref, "b", n, style), nil
+	case int, int8, int16, int32, int64:
+		n := reflect.ValueOf(x).Int()
+		if math.Abs(float64(n)) >= xlsxMaxExactNumber {
+			return appendXLSXString(b, ref, strconv.FormatInt(n, 10), style), nil
+		}
+		return appendXLSXValue(b, ref, "", strconv.FormatInt(n, 10), style), nil
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		n := reflect.ValueOf(x).Uint()
+		if float64(n) >= xlsxMaxExactNumber {
+			return appendXLSXString(b, ref, strconv.FormatUint(n, 10), style), nil
+		}
+		return appendXLSXValue(b, ref, "", strconv.FormatUint(n, 10), style), nil
+	case float32:
+		return appendXLSXFloat(b, ref, float64(x), 32, style), nil
+	case float64:
+		return appendXLSXFloat(b, ref, x, 64, style), nil
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil || math.IsInf(f, 0) || (!strings.ContainsAny(x.String(), ".eE") && math.Abs(f) >= xlsxMaxExactNumber) {
+			return appendXLSXString(b, ref, x.String(), style), nil
+		}
+		return appendXLSXValue(b, ref, "", x.String(), style), nil
+	case decimal.Decimal:
+		return appendXLSXValue(b, ref, "", x.String(), style), nil
+	case time.Time:
+		if x.IsZero() {
+			return b, nil
+		}
+		if style == 0 {
+			style = xlsxStyleDate
+		}
+		return appendXLSXValue(b, ref, "", strconv.FormatFloat(xlsxDateSerial(x), 'f', -1, 64), style), nil
+	case fmt.Stringer:
+		return appendXLSXString(b, ref, x.String(), style), nil
+	}
+	// 自定义的数字、布尔类型按底层类型写入
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return appendXLSXCell(b, ref, rv.Bool(), style)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendXLSXCell(b, ref, rv.Int(), style)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendXLSXCell(b, ref, rv.Uint(), style)
+	case reflect.Float32, reflect.Float64:
+		return appendXLSXCell(b, ref, rv.Float(), style)
+	case reflect.String:
+		return appendXLSXString(b, ref, rv.String(), style), nil
+	}
+	s, err := formatCSVValue(v)
+	if err != nil {
+		return b, err
+	}
+	return appendXLSXString(b, ref, s, style), nil
+}
+
+func appendXLSXFloat(b []byte, ref string, f float64, bitSize int, style int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendXLSXString(b, ref, strconv.FormatFloat(f, 'g', -1, bitSize), style)
+	}
+	return appendXLSXValue(b, ref, "", strconv.FormatFloat(f, 'g', -1, bitSize), style)
+}
+
+// appendXLSXValue 写入 <c> 单元格，typ 为空表示数值
+func appendXLSXValue(b []byte, ref, typ, value string, style int) []byte {
+	b = appendXLSXCellStart(b, ref, typ, style)
+	b = append(b, `<v>`...)
+	b = append(b, value...)
+	return append(b, `</v></c>`...)
+}
+
+// appendXLSXString 以内联字符串写入文本，不使用共享字符串表以便流式写入
+func appendXLSXString(b []byte, ref, s string, style int) []byte {
+	b = appendXLSXCellStart(b, ref, "inlineStr", style)
+	b = append(b, `<is><t xml:space="preserve">`...)
+	b = appendXMLEscaped(b, s)
+	return append(b, `</t></is></c>`...)
+}
+
+func appendXLSXCellStart(b []byte, ref, typ string, style int) []byte {
+	b = append(b, `<c r="`...)
+	b = append(b, ref...)
+	b = append(b, '"')
+	if style != 0 {
+		b = fmt.Appendf(b, ` s="%d"`, style)
+	}
+	if typ != "" {
+		b = append(b, ` t="`...)
+		b = append(b, typ...)
+		b = append(b, '"')
+	}
+	return append(b, '>')
+}
+
+// appendXMLEscaped 转义 XML 特殊字符，XML 中不允许的控制字符替换为 U+FFFD
+func appendXMLEscaped(b []byte, s string) []byte {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return append(b, sb.String()...)
+}
+
+// xlsxCellRef 返回单元格引用，如 (0, 1) -> A1，col 从 0 开始
+func xlsxCellRef(col, row int) string {
+	var letters [3]byte
+	i := len(letters)
+	for col++; col > 0; col = (col - 1) / 26 {
+		i--
+		letters[i] = byte('A' + (col-1)%26)
+	}
+	return string(letters[i:]) + strconv.Itoa(row)
+}
+
+// xlsxDateSerial 将时间按其所在时区的日期和时刻转换为 Excel 日期序列号
+func xlsxDateSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(xlsxEpoch).Hours() / 24
+}
+
+// xlsxAutoWidth 按表头的显示宽度估算列宽，中日韩等宽字符计为 2
+func xlsxAutoWidth(header string) float64 {
+	width := 0
+	for _, r := range header {
+		if r >= 0x1100 {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return float64(min(max(width+2, 10), 60))
+}
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func (x *XLSXWriter) contentTypes() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range x.sheets {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func (x *XLSXWriter) workbook() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range x.sheets {
+		sb.WriteString(`<sheet name="`)
+		_ = xml.EscapeText(&sb, []byte(name))
+		fmt.Fprintf(&sb, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func (x *XLSXWriter) workbookRels() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range x.sheets {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(x.sheets)+1)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// styles 样式表：cellXfs 依次为默认、加粗表头、日期
+func (x *XLSXWriter) styles() string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<numFmts count="1"><numFmt numFmtId="164" formatCode="`)
+	_ = xml.EscapeText(&sb, []byte(x.opts.dateFormat))
+	sb.WriteString(`"/></numFmts>`)
+	sb.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>`)
+	sb.WriteString(`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>`)
+	sb.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	sb.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	sb.WriteString(`<cellXfs count="3">`)
+	sb.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`)
+	sb.WriteString(`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>`)
+	sb.WriteString(`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`)
+	sb.WriteString(`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+	return sb.String()
+}
+
+// JsonArrayToXLSX 将对象数组写入只有一个工作表的 XLSX 到 w，第一行为表头。
+// 列由 WithXLSXColumns 指定，否则为所有字段按字段名排序；单元格类型规则见 XLSXWriter.WriteRow
+func JsonArrayToXLSX(w io.Writer, jsonArray []map[string]interface{}, opts ...XLSXOption) error {
+	x := NewXLSXWriter(w, opts...)
+	rows := make([]map[string]interface{}, len(jsonArray))
+	for i, obj := range jsonArray {
+		rows[i] = x.opts.prepare(obj)
+	}
+	columns := x.opts.columns
+	if len(columns) == 0 {
+		columns = collectColumns(rows)
+	}
+	// 已展开，避免 WriteMap 重复处理
+	if err := x.AddSheet(x.opts.sheetName, columns, WithXLSXNested(NestedJSON)); err != nil {
+		return err
+	}
+	for _, obj := range rows {
+		if err := x.WriteMap(obj); err != nil {
+			return err
+		}
+	}
+	return x.Close()
+}
+
+// StructsToXLSX 将结构体切片写入只有一个工作表的 XLSX 到 w，第一行为表头，列规则见 AddStructSheet
+func StructsToXLSX[T any](w io.Writer, rows []T, opts ...XLSXOption) error {
+	x := NewXLSXWriter(w, opts...)
+	if err := AddStructSheet[T](x, x.opts.sheetName); err != nil {
+		return err
+	}
+	for i := range rows {
+		if err := x.WriteStruct(&rows[i]); err != nil {
+			return err
+		}
+	}
+	return x.Close()
+}
@@ -0,0 +1,168 @@
+package data
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// readXLSX 解压工作簿并校验每个部件都是合法的 XML
+func readXLSX(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		_ = rc.Close()
+
+		dec := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err := dec.Token(); err != nil {
+				assert.ErrorIs(t, err, io.EOF, f.Name)
+				break
+			}
+		}
+		parts[f.Name] = string(b)
+	}
+	return parts
+}
+
+func TestJsonArrayToXLSX(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"name": "张三 <a&b>", "age": 25, "score": 85.5, "active": true, "price": decimal.RequireFromString("12.30"),
+			"created": time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), "id": json.Number("12345678901234567890"),
+			"tags": []interface{}{"a"}},
+		{"name": "李四", "age": nil, "created": time.Time{}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, JsonArrayToXLSX(&buf, rows,
+		WithXLSXSheetName("用户"),
+		WithXLSXColumns("name", "age", "score", "active", "price", "created", "id", "tags"),
+		WithXLSXColumnNames(map[string]string{"name": "姓名"}),
+		WithXLSXColumnWidths(map[string]float64{"created": 20}),
+	))
+	parts := readXLSX(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, parts, name)
+	}
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="用户" sheetId="1" r:id="rId1"/>`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	assert.Contains(t, sheet, `<col min="1" max="1" width="10" customWidth="1"/>`)
+	assert.Contains(t, sheet, `<col min="6" max="6" width="20" customWidth="1"/>`)
+	assert.Contains(t, sheet, `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">姓名</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">张三 &lt;a&amp;b&gt;</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B2"><v>25</v></c>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>85.5</v></c>`)
+	assert.Contains(t, sheet, `<c r="D2" t="b"><v>1</v></c>`)
+	assert.Contains(t, sheet, `<c r="E2"><v>12.3</v></c>`)
+	assert.Contains(t, sheet, `<c r="F2" s="2"><v>45293.5</v></c>`)
+	assert.Contains(t, sheet, `<c r="G2" t="inlineStr"><is><t xml:space="preserve">12345678901234567890</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="H2" t="inlineStr"><is><t xml:space="preserve">[&#34;a&#34;]</t></is></c>`)
+	assert.Contains(t, sheet, `<row r="3"><c r="A3" t="inlineStr"><is><t xml:space="preserve">李四</t></is></c></row>`)
+
+	// 嵌套展开与默认列
+	buf.Reset()
+	assert.NoError(t, JsonArrayToXLSX(&buf, []map[string]interface{}{{"user": map[string]interface{}{"name": "alice"}}},
+		WithXLSXNested(NestedFlatten), WithXLSXFreezeHeader(false)))
+	sheet = readXLSX(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<t xml:space="preserve">user.name</t>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">alice</t>`)
+	assert.NotContains(t, sheet, `<pane`)
+}
+
+type xlsxStatus int
+
+type xlsxOrder struct {
+	csvAudit
+	Product string          `csv:"product"`
+	Amount  decimal.Decimal `csv:"amount"`
+	Status  xlsxStatus      `csv:"status"`
+	Paid    *time.Time      `csv:"paid_at"`
+	Note    string          `csv:"-"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	paid := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	var buf bytes.Buffer
+	x := NewXLSXWriter(&buf, WithXLSXDateFormat("yyyy-mm-dd"))
+
+	assert.Error(t, x.WriteRow("x"))
+	assert.NoError(t, AddStructSheet[xlsxOrder](x, "订单"))
+	assert.NoError(t, x.WriteStruct(xlsxOrder{csvAudit: csvAudit{ID: 1}, Product: "书", Amount: decimal.NewFromInt(30), Status: 2, Paid: &paid}))
+	assert.NoError(t, x.WriteStruct(&xlsxOrder{Product: "笔"}))
+	assert.Error(t, x.WriteStruct(1))
+
+	assert.Error(t, x.AddSheet("订单", nil))
+	assert.Error(t, x.AddSheet("a/b", nil))
+	assert.Error(t, x.AddSheet(strings.Repeat("a", 32), nil))
+	assert.NoError(t, x.AddSheet("Raw", []string{"k", "v"}))
+	assert.NoError(t, x.WriteMap(map[string]interface{}{"k": "a", "v": 1.5, "extra": 1}))
+	assert.NoError(t, x.WriteRow("b", nil, "extra"))
+	assert.Error(t, x.WriteStruct(xlsxOrder{}))
+	assert.NoError(t, x.AddSheet("Empty", nil))
+	assert.NoError(t, x.Close())
+	assert.NoError(t, x.Close())
+	assert.Error(t, x.WriteRow("x"))
+
+	parts := readXLSX(t, buf.Bytes())
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Raw" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, parts["xl/_rels/workbook.xml.rels"], `Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"`)
+	assert.Contains(t, parts["[Content_Types].xml"], `/xl/worksheets/sheet3.xml`)
+	assert.Contains(t, parts["xl/styles.xml"], `formatCode="yyyy-mm-dd"`)
+
+	orders := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, orders, `tabSelected="1"`)
+	assert.Contains(t, orders, `<t xml:space="preserve">paid_at</t>`)
+	assert.NotContains(t, orders, `Note`)
+	assert.Contains(t, orders, `<c r="A2"><v>1</v></c>`)
+	assert.Contains(t, orders, `<c r="C2"><v>30</v></c>`)
+	assert.Contains(t, orders, `<c r="D2"><v>2</v></c>`)
+	assert.Contains(t, orders, `<c r="E2" s="2"><v>45352</v></c>`)
+	assert.Contains(t, orders, `<row r="3"><c r="A3"><v>0</v></c><c r="B3" t="inlineStr"><is><t xml:space="preserve">笔</t></is></c><c r="C3"><v>0</v></c><c r="D3"><v>0</v></c></row>`)
+
+	raw := parts["xl/worksheets/sheet2.xml"]
+	assert.NotContains(t, raw, `tabSelected`)
+	assert.Contains(t, raw, `<row r="2"><c r="A2" t="inlineStr"><is><t xml:space="preserve">a</t></is></c><c r="B2"><v>1.5</v></c></row>`)
+	assert.Contains(t, raw, `<c r="C3" t="inlineStr">`)
+
+	assert.Contains(t, parts["xl/worksheets/sheet3.xml"], `<sheetData></sheetData>`)
+}
+
+func TestStructsToXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, StructsToXLSX(&buf, []xlsxOrder{{Product: "书"}}, WithXLSXColumns("product", "id")))
+	sheet := readXLSX(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">product</t></is></c><c r="B1" s="1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c></row>`)
+
+	assert.Error(t, StructsToXLSX(io.Discard, []xlsxOrder{}, WithXLSXColumns("missing")))
+	assert.Error(t, StructsToXLSX(io.Discard, []int{1}))
+
+	// 未添加工作表时生成空工作表
+	buf.Reset()
+	assert.NoError(t, NewXLSXWriter(&buf).Close())
+	assert.Contains(t, readXLSX(t, buf.Bytes())["xl/workbook.xml"], `name="Sheet1"`)
+}
+
+func TestXLSXCellRef(t *testing.T) {
+	assert.Equal(t, "A1", xlsxCellRef(0, 1))
+	assert.Equal(t, "Z2", xlsxCellRef(25, 2))
+	assert.Equal(t, "AA3", xlsxCellRef(26, 3))
+	assert.Equal(t, "XFD4", xlsxCellRef(16383, 4))
+}
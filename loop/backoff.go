@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff 退避策略，决定每次失败后到下一次重试前的等待时间
+type Backoff interface {
+	// Next 返回第 attempt 次（从1开始）失败后的等待时间，prev 为上一次的等待时间（首次为0）
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// BackoffFunc 将普通函数适配为 Backoff
+type BackoffFunc func(attempt int, prev time.Duration) time.Duration
+
+// Next 实现 Backoff 接口
+func (f BackoffFunc) Next(attempt int, prev time.Duration) time.Duration {
+	return f(attempt, prev)
+}
+
+// Jitter 抖动模式，用于打散大量客户端同时重试造成的请求尖峰
+type Jitter int
+
+const (
+	// NoJitter 不加抖动
+	NoJitter Jitter = iota
+	// FullJitter 在 [0, d] 之间随机
+	FullJitter
+	// EqualJitter 在 [d/2, d] 之间随机
+	EqualJitter
+	// DecorrelatedJitter 在 [base, prev*3] 之间随机，并受 max 限制
+	DecorrelatedJitter
+)
+
+// ConstantBackoff 固定等待时间
+func ConstantBackoff(delay time.Duration) Backoff {
+	return BackoffFunc(func(int, time.Duration) time.Duration {
+		return delay
+	})
+}
+
+// LinearBackoff 线性增长的等待时间：initial + step*(attempt-1)，max<=0 表示不设上限
+func LinearBackoff(initial, step, max time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		if attempt < 1 {
+			attempt = 1
+		}
+		n := time.Duration(attempt - 1)
+		if step > 0 && n > (math.MaxInt64-initial)/step {
+			return capDuration(math.MaxInt64, max)
+		}
+		return capDuration(initial+step*n, max)
+	})
+}
+
+// ExponentialBackoff 指数增长的等待时间：base * 2^(attempt-1)，max<=0 表示不设上限
+func ExponentialBackoff(base, max time.Duration, jitter Jitter) Backoff {
+	return BackoffFunc(func(attempt int, prev time.Duration) time.Duration {
+		if jitter == DecorrelatedJitter {
+			// 参考 AWS Architecture Blog: sleep = min(cap, random_between(base, sleep*3))
+			if prev < base {
+				prev = base
+			}
+			upper := prev
+			if upper <= math.MaxInt64/3 {
+				upper *= 3
+			} else {
+				upper = math.MaxInt64
+			}
+			return capDuration(randBetween(base, upper), max)
+		}
+
+		d := capDuration(exponential(base, attempt), max)
+		switch jitter {
+		case FullJitter:
+			return randBetween(0, d)
+		case EqualJitter:
+			return d/2 + randBetween(0, d-d/2)
+		default:
+			return d
+		}
+	})
+}
+
+// exponential 计算 base * 2^(attempt-1)，溢出时返回最大值
+func exponential(base time.Duration, attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	shift := attempt - 1
+	if base <= 0 {
+		return 0
+	}
+	if shift >= 63 || base > math.MaxInt64>>shift {
+		return math.MaxInt64
+	}
+	return base << shift
+}
+
+// capDuration 将 d 限制在 [0, max] 范围内，max<=0 表示不设上限
+func capDuration(d, max time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
+
+// randBetween 返回 [lo, hi] 之间的随机时长
+func randBetween(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	span := int64(hi - lo)
+	if span == math.MaxInt64 {
+		return lo + time.Duration(rand.Int64N(span))
+	}
+	return lo + time.Duration(rand.Int64N(span+1))
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态（或半开状态下探测名额已满）时返回的错误
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State 熔断器状态
+type State int
+
+const (
+	// StateClosed 关闭：请求正常通过并统计失败
+	StateClosed State = iota
+	// StateOpen 打开：请求直接失败，冷却结束后进入半开
+	StateOpen
+	// StateHalfOpen 半开：放行有限的探测请求，全部成功则关闭，任意失败则重新打开
+	StateHalfOpen
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerSettings 熔断器配置
+type BreakerSettings struct {
+	Name string // 名称，传给 OnStateChange
+
+	// ConsecutiveFailures 连续失败达到该次数时熔断；与 FailureRatio 都未设置时默认为 5
+	ConsecutiveFailures int
+	// FailureRatio 统计窗口内失败率达到该值（0~1）时熔断，0 表示不启用
+	FailureRatio float64
+	// MinRequests 按失败率熔断所需的最少请求数，默认为 1
+	MinRequests int
+	// Window 关闭状态下的统计窗口，每个窗口结束时清零计数，0 表示不清零
+	Window time.Duration
+
+	// CoolDown 打开状态持续时间，之后进入半开状态，默认 30s
+	CoolDown time.Duration
+	// HalfOpenMaxProbes 半开状态下放行的探测请求数，全部成功后关闭熔断器，默认 1
+	HalfOpenMaxProbes int
+
+	// IsFailure 判断错误是否计为失败，默认 err != nil
+	IsFailure func(err error) bool
+	// OnStateChange 状态变化回调，在锁外同步调用
+	OnStateChange func(name string, from, to State)
+}
+
+// Counts 熔断器当前统计窗口内的计数
+type Counts struct {
+	Requests             int
+	Successes            int
+	Failures             int
+	ConsecutiveSuccesses int
+	ConsecutiveFailures  int
+}
+
+// CircuitBreaker 熔断器，可并发使用
+type CircuitBreaker struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu          sync.Mutex
+	state       State
+	generation  uint64
+	counts      Counts
+	windowStart time.Time
+	openedAt    time.Time
+	probes      int // 半开状态下已放行的探测请求数
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(settings BreakerSettings) *CircuitBreaker {
+	if settings.ConsecutiveFailures <= 0 && settings.FailureRatio <= 0 {
+		settings.ConsecutiveFailures = 5
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 1
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = 30 * time.Second
+	}
+	if settings.HalfOpenMaxProbes <= 0 {
+		settings.HalfOpenMaxProbes = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = func(err error) bool { return err != nil }
+	}
+	cb := &CircuitBreaker{settings: settings, now: time.Now}
+	cb.windowStart = cb.now()
+	return cb
+}
+
+// Name 返回熔断器名称
+func (cb *CircuitBreaker) Name() string {
+	return cb.settings.Name
+}
+
+// State 返回当前状态
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	changes := cb.refresh(cb.now())
+	state := cb.state
+	cb.mu.Unlock()
+	cb.notify(changes)
+	return state
+}
+
+// Counts 返回当前统计窗口内的计数
+func (cb *CircuitBreaker) Counts() Counts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.counts
+}
+
+// Allow 判断是否放行一次请求，放行时返回的 done 必须在请求结束后以其结果调用一次
+// 熔断器打开时返回 ErrCircuitOpen
+func (cb *CircuitBreaker) Allow() (done func(err error), err error) {
+	cb.mu.Lock()
+	now := cb.now()
+	changes := cb.refresh(now)
+	switch {
+	case cb.state == StateOpen:
+		err = ErrCircuitOpen
+	case cb.state == StateHalfOpen && cb.probes >= cb.settings.HalfOpenMaxProbes:
+		err = ErrCircuitOpen
+	case cb.state == StateHalfOpen:
+		cb.probes++
+	}
+	if err == nil {
+		cb.counts.Requests++
+	}
+	generation := cb.generation
+	cb.mu.Unlock()
+	cb.notify(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { cb.record(generation, err) })
+	}, nil
+}
+
+// Execute 在熔断器保护下执行 fn，熔断器打开时直接返回 ErrCircuitOpen 而不调用 fn
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err)
+	return err
+}
+
+// Reset 将熔断器重置为关闭状态
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	changes := cb.setState(StateClosed, cb.now())
+	cb.mu.Unlock()
+	cb.notify(changes)
+}
+
+// record 记录一次请求结果，generation 不一致说明状态已变化，结果被丢弃
+func (cb *CircuitBreaker) record(generation uint64, err error) {
+	cb.mu.Lock()
+	now := cb.now()
+	changes := cb.refresh(now)
+	if generation != cb.generation {
+		cb.mu.Unlock()
+		cb.notify(changes)
+		return
+	}
+
+	if cb.settings.IsFailure(err) {
+		cb.counts.Failures++
+		cb.counts.ConsecutiveFailures++
+		cb.counts.ConsecutiveSuccesses = 0
+		switch cb.state {
+		case StateClosed:
+			if cb.shouldTrip() {
+				changes = append(changes, cb.setState(StateOpen, now)...)
+			}
+		case StateHalfOpen:
+			changes = append(changes, cb.setState(StateOpen, now)...)
+		}
+	} else {
+		cb.counts.Successes++
+		cb.counts.ConsecutiveSuccesses++
+		cb.counts.ConsecutiveFailures = 0
+		if cb.state == StateHalfOpen && cb.counts.ConsecutiveSuccesses >= cb.settings.HalfOpenMaxProbes {
+			changes = append(changes, cb.setState(StateClosed, now)...)
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(changes)
+}
+
+// shouldTrip 判断关闭状态下是否满足熔断条件
+func (cb *CircuitBreaker) shouldTrip() bool {
+	s := cb.settings
+	if s.ConsecutiveFailures > 0 && cb.counts.ConsecutiveFailures >= s.ConsecutiveFailures {
+		return true
+	}
+	if s.FailureRatio > 0 && cb.counts.Requests >= s.MinRequests {
+		return float64(cb.counts.Failures)/float64(cb.counts.Requests) >= s.FailureRatio
+	}
+	return false
+}
+
+type stateChange struct {
+	from, to State
+}
+
+// refresh 处理随时间发生的状态变化：冷却结束进入半开、统计窗口到期清零
+func (cb *CircuitBreaker) refresh(now time.Time) []stateChange {
+	switch cb.state {
+	case StateOpen:
+		if !now.Before(cb.openedAt.Add(cb.settings.CoolDown)) {
+			return cb.setState(StateHalfOpen, now)
+		}
+	case StateClosed:
+		if cb.settings.Window > 0 && !now.Before(cb.windowStart.Add(cb.settings.Window)) {
+			cb.generation++
+			cb.counts = Counts{}
+			cb.windowStart = now
+		}
+	}
+	return nil
+}
+
+// setState 切换状态并清零计数
+func (cb *CircuitBreaker) setState(state State, now time.Time) []stateChange {
+	if cb.state == state {
+		return nil
+	}
+	from := cb.state
+	cb.state = state
+	cb.generation++
+	cb.counts = Counts{}
+	cb.windowStart = now
+	cb.probes = 0
+	if state == StateOpen {
+		cb.openedAt = now
+	}
+	return []stateChange{{from: from, to: state}}
+}
+
+func (cb *CircuitBreaker) notify(changes []stateChange) {
+	if cb.settings.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		cb.settings.OnStateChange(cb.settings.Name, c.from, c.to)
+	}
+}
+
+// WithCircuitBreaker 每次尝试前经过熔断器，熔断器打开时立即放弃且不消耗尝试次数，返回的错误包含 ErrCircuitOpen
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(o *retryOptions) {
+		o.breaker = cb
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNow 可手动推进的时钟
+type fakeNow struct {
+	t time.Time
+}
+
+func (f *fakeNow) Now() time.Time { return f.t }
+
+func (f *fakeNow) Advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func newTestBreaker(settings BreakerSettings) (*CircuitBreaker, *fakeNow) {
+	clock := &fakeNow{t: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(settings)
+	cb.now = clock.Now
+	cb.windowStart = clock.Now()
+	return cb, clock
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	errFail := errors.New("fail")
+	fail := func() error { return errFail }
+	ok := func() error { return nil }
+
+	t.Run("TC01: 连续失败熔断并在冷却后恢复", func(t *testing.T) {
+		var changes []string
+		cb, clock := newTestBreaker(BreakerSettings{
+			Name:                "test",
+			ConsecutiveFailures: 3,
+			CoolDown:            time.Second,
+			HalfOpenMaxProbes:   2,
+			OnStateChange: func(name string, from, to State) {
+				changes = append(changes, from.String()+"->"+to.String())
+			},
+		})
+		for i := 0; i < 3; i++ {
+			assert.ErrorIs(t, cb.Execute(fail), errFail)
+		}
+		assert.Equal(t, StateOpen, cb.State())
+		assert.ErrorIs(t, cb.Execute(ok), ErrCircuitOpen)
+
+		clock.Advance(time.Second)
+		assert.Equal(t, StateHalfOpen, cb.State())
+		done1, err := cb.Allow()
+		assert.NoError(t, err)
+		done2, err := cb.Allow()
+		assert.NoError(t, err)
+		_, err = cb.Allow()
+		assert.ErrorIs(t, err, ErrCircuitOpen) // 探测名额已满
+		done1(nil)
+		done2(nil)
+		assert.Equal(t, StateClosed, cb.State())
+		assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes)
+	})
+
+	t.Run("TC02: 半开探测失败重新打开", func(t *testing.T) {
+		cb, clock := newTestBreaker(BreakerSettings{ConsecutiveFailures: 1, CoolDown: time.Second})
+		_ = cb.Execute(fail)
+		clock.Advance(time.Second)
+		_ = cb.Execute(fail)
+		assert.Equal(t, StateOpen, cb.State())
+	})
+
+	t.Run("TC03: 按失败率熔断", func(t *testing.T) {
+		cb, _ := newTestBreaker(BreakerSettings{FailureRatio: 0.5, MinRequests: 4})
+		_ = cb.Execute(ok)
+		_ = cb.Execute(fail)
+		_ = cb.Execute(ok)
+		assert.Equal(t, StateClosed, cb.State())
+		_ = cb.Execute(fail)
+		assert.Equal(t, StateOpen, cb.State())
+	})
+
+	t.Run("TC04: 统计窗口到期清零", func(t *testing.T) {
+		cb, clock := newTestBreaker(BreakerSettings{ConsecutiveFailures: 2, Window: time.Minute})
+		_ = cb.Execute(fail)
+		clock.Advance(time.Minute)
+		_ = cb.Execute(fail)
+		assert.Equal(t, StateClosed, cb.State())
+		assert.Equal(t, 1, cb.Counts().Failures)
+	})
+
+	t.Run("TC05: 与重试组合时快速失败", func(t *testing.T) {
+		cb, _ := newTestBreaker(BreakerSettings{ConsecutiveFailures: 2})
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errFail
+		}, WithMaxAttempts(10), WithBackoff(ConstantBackoff(0)), WithCircuitBreaker(cb))
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.ErrorIs(t, err, errFail)
+		assert.Equal(t, 2, calls)
+	})
+}
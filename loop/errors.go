@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// permanentError 标记不应重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 将错误标记为永久错误，重试会立即停止并返回原始错误；err 为 nil 时返回 nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断错误是否被标记为永久错误
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// retryAfterError 携带服务端指定等待时间的错误
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.err, e.delay)
+}
+
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// RetryAfter 为错误附加服务端指定的等待时间（如 HTTP Retry-After），下一次重试会等待该时间而不是退避策略给出的时间
+// 此类错误总会被重试，不经过 ShouldRetry 判断；err 为 nil 时返回 nil
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// RetryAfterDelay 获取错误上附加的等待时间
+func RetryAfterDelay(err error) (time.Duration, bool) {
+	var re *retryAfterError
+	if errors.As(err, &re) {
+		return re.delay, true
+	}
+	return 0, false
+}
+
+// StatusCoder 携带 HTTP 状态码的错误
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// statusError 附带 HTTP 状态码的错误
+type statusError struct {
+	err  error
+	code int
+}
+
+func (e *statusError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("HTTP %d %s", e.code, http.StatusText(e.code))
+	}
+	return fmt.Sprintf("HTTP %d: %v", e.code, e.err)
+}
+
+func (e *statusError) Unwrap() error { return e.err }
+
+func (e *statusError) StatusCode() int { return e.code }
+
+// StatusError 构造携带 HTTP 状态码的错误，便于 IsRetryableHTTPStatus 分类，err 可以为 nil
+func StatusError(code int, err error) error {
+	return &statusError{err: err, code: code}
+}
+
+// WithShouldRetry 设置错误分类函数，返回 false 的错误会立即停止重试；默认所有错误都重试
+func WithShouldRetry(shouldRetry func(err error) bool) Option {
+	return func(o *retryOptions) {
+		o.shouldRetry = shouldRetry
+	}
+}
+
+// AnyOf 组合多个分类函数，任意一个返回 true 即重试
+func AnyOf(classifiers ...func(err error) bool) func(err error) bool {
+	return func(err error) bool {
+		for _, c := range classifiers {
+			if c(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsTimeout 判断是否为 net.Error 超时错误
+func IsTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// IsDeadlineExceeded 判断是否为 context.DeadlineExceeded
+func IsDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsRetryableStatusCode 判断 HTTP 状态码是否值得重试：429 以及除 501、505 以外的 5xx
+func IsRetryableStatusCode(code int) bool {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusNotImplemented, code == http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return code >= 500 && code <= 599
+	}
+}
+
+// IsRetryableHTTPStatus 判断错误链中携带的 HTTP 状态码（StatusCoder）是否值得重试
+func IsRetryableHTTPStatus(err error) bool {
+	var sc StatusCoder
+	return errors.As(err, &sc) && IsRetryableStatusCode(sc.StatusCode())
+}
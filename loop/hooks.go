@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RetryEvent 重试过程中的事件信息
+type RetryEvent struct {
+	Attempt   int           // 已执行的尝试次数（从1开始）
+	Elapsed   time.Duration // 从第一次调用开始的耗时
+	NextDelay time.Duration // 下一次重试前的等待时间，仅 OnRetry 时有值
+	Err       error         // OnRetry 时为本次错误，OnGiveUp 时为最终返回的错误，OnSuccess 时为 nil
+}
+
+// Hooks 重试观测钩子，未设置的钩子会被忽略
+type Hooks struct {
+	OnRetry   func(RetryEvent) // 失败且即将等待重试时调用
+	OnGiveUp  func(RetryEvent) // 放弃重试时调用
+	OnSuccess func(RetryEvent) // 成功时调用
+}
+
+// WithHooks 追加一组观测钩子，多次设置时按顺序全部调用
+func WithHooks(h Hooks) Option {
+	return func(o *retryOptions) {
+		o.hooks = append(o.hooks, h)
+	}
+}
+
+// WithOnRetry 追加 OnRetry 钩子
+func WithOnRetry(fn func(RetryEvent)) Option {
+	return WithHooks(Hooks{OnRetry: fn})
+}
+
+// WithOnGiveUp 追加 OnGiveUp 钩子
+func WithOnGiveUp(fn func(RetryEvent)) Option {
+	return WithHooks(Hooks{OnGiveUp: fn})
+}
+
+// WithOnSuccess 追加 OnSuccess 钩子
+func WithOnSuccess(fn func(RetryEvent)) Option {
+	return WithHooks(Hooks{OnSuccess: fn})
+}
+
+// WithSlog 使用 slog 记录重试事件，等价于 WithHooks(SlogHooks(logger))
+func WithSlog(logger *slog.Logger) Option {
+	return WithHooks(SlogHooks(logger))
+}
+
+// SlogHooks 返回输出 slog 记录的钩子：重试为 Warn，放弃为 Error，经过重试后成功为 Info（首次即成功不记录）
+// logger 为 nil 时使用 slog.Default()
+func SlogHooks(logger *slog.Logger) Hooks {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return Hooks{
+		OnRetry: func(e RetryEvent) {
+			logger.LogAttrs(context.Background(), slog.LevelWarn, "retry attempt failed",
+				slog.Int("attempt", e.Attempt),
+				slog.Duration("elapsed", e.Elapsed),
+				slog.Duration("next_delay", e.NextDelay),
+				slog.Any("error", e.Err),
+			)
+		},
+		OnGiveUp: func(e RetryEvent) {
+			logger.LogAttrs(context.Background(), slog.LevelError, "retry gave up",
+				slog.Int("attempt", e.Attempt),
+				slog.Duration("elapsed", e.Elapsed),
+				slog.Any("error", e.Err),
+			)
+		},
+		OnSuccess: func(e RetryEvent) {
+			if e.Attempt <= 1 {
+				return
+			}
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "retry succeeded",
+				slog.Int("attempt", e.Attempt),
+				slog.Duration("elapsed", e.Elapsed),
+			)
+		},
+	}
+}
+
+func (o *retryOptions) onRetry(e RetryEvent) {
+	for _, h := range o.hooks {
+		if h.OnRetry != nil {
+			h.OnRetry(e)
+		}
+	}
+}
+
+func (o *retryOptions) onGiveUp(e RetryEvent) {
+	for _, h := range o.hooks {
+		if h.OnGiveUp != nil {
+			h.OnGiveUp(e)
+		}
+	}
+}
+
+func (o *retryOptions) onSuccess(e RetryEvent) {
+	for _, h := range o.hooks {
+		if h.OnSuccess != nil {
+			h.OnSuccess(e)
+		}
+	}
+}
@@ -1,20 +1,219 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 )
 
-// Retry 函数接受一个要执行的操作（operation），最大尝试次数（maxRetries）和两次尝试之间的等待时间（delay）
-func Retry(operation func() error, maxRetries int, delay time.Duration) {
-	for retries := 0; retries < maxRetries; retries++ {
-		err := operation()
-		if err == nil { // 如果操作成功，返回nil
-			return
+// DefaultMaxAttempts RetryWithContext 默认的最大尝试次数
+const DefaultMaxAttempts = 3
+
+// Option RetryWithContext 的配置项
+type Option func(*retryOptions)
+
+type retryOptions struct {
+	maxAttempts    int
+	backoff        Backoff
+	maxElapsedTime time.Duration
+	joinErrors     bool
+	shouldRetry    func(err error) bool
+	hooks          []Hooks
+	stats          *Stats
+	breaker        *CircuitBreaker
+}
+
+// Stats 一次重试调用的统计信息
+type Stats struct {
+	Attempts int           // 实际执行的尝试次数
+	Elapsed  time.Duration // 从第一次调用到结束的总耗时
+}
+
+func newRetryOptions(opts []Option) *retryOptions {
+	o := &retryOptions{
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     ExponentialBackoff(100*time.Millisecond, 10*time.Second, FullJitter),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.backoff == nil {
+		o.backoff = ConstantBackoff(0)
+	}
+	return o
+}
+
+// WithMaxAttempts 设置最大尝试次数（包含第一次调用），n<=0 表示不限次数，此时应配合 context 或 WithMaxElapsedTime 使用
+func WithMaxAttempts(n int) Option {
+	return func(o *retryOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// WithBackoff 设置退避策略
+func WithBackoff(b Backoff) Option {
+	return func(o *retryOptions) {
+		o.backoff = b
+	}
+}
+
+// WithMaxElapsedTime 设置从第一次调用开始允许的最长总耗时，下一次等待会超出该时间时直接放弃
+func WithMaxElapsedTime(d time.Duration) Option {
+	return func(o *retryOptions) {
+		o.maxElapsedTime = d
+	}
+}
+
+// WithStats 在调用结束时将统计信息写入 stats
+func WithStats(stats *Stats) Option {
+	return func(o *retryOptions) {
+		o.stats = stats
+	}
+}
+
+// WithJoinErrors 放弃时返回所有尝试的错误（errors.Join），默认只返回最后一次的错误
+func WithJoinErrors() Option {
+	return func(o *retryOptions) {
+		o.joinErrors = true
+	}
+}
+
+// RetryWithContext 执行 operation 直到成功、达到最大尝试次数、超出最长耗时或 ctx 结束
+// 成功返回 nil；放弃时返回最后一次的错误（或 WithJoinErrors 下的全部错误）；
+// operation 返回 Permanent 包装的错误时立即停止并返回原始错误，返回 RetryAfter 包装的错误时按其指定时间等待；
+// ctx 结束时返回的错误同时包含 ctx.Err() 和最后一次的错误，可用 errors.Is 判断
+func RetryWithContext(ctx context.Context, operation func(ctx context.Context) error, opts ...Option) error {
+	_, err := RetryValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, operation(ctx)
+	}, opts...)
+	return err
+}
+
+// RetryValue 与 RetryWithContext 相同，但 operation 会返回一个值，成功时返回该值，失败时返回 T 的零值
+// 尝试次数、耗时等统计信息可通过 WithStats 获取
+func RetryValue[T any](ctx context.Context, operation func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	o := newRetryOptions(opts)
+	start := time.Now()
+
+	var zero T
+	var errs []error
+	var delay time.Duration
+	attempt := 0
+	finish := func() {
+		if o.stats != nil {
+			*o.stats = Stats{Attempts: attempt, Elapsed: time.Since(start)}
+		}
+	}
+	giveUp := func(cause error) (T, error) {
+		finish()
+		err := o.giveUp(errs, cause)
+		o.onGiveUp(RetryEvent{Attempt: attempt, Elapsed: time.Since(start), Err: err})
+		return zero, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return giveUp(err)
 		}
 
-		// 如果操作失败，打印错误并等待指定的延迟时间后重试
-		fmt.Printf("Attempt %d failed; error: %v\n", retries+1, err)
-		time.Sleep(delay)
+		var done func(err error)
+		if o.breaker != nil {
+			var err error
+			if done, err = o.breaker.Allow(); err != nil {
+				return giveUp(err)
+			}
+		}
+
+		attempt++
+		value, err := operation(ctx)
+		if done != nil {
+			done(err)
+		}
+		if err == nil {
+			finish()
+			o.onSuccess(RetryEvent{Attempt: attempt, Elapsed: time.Since(start)})
+			return value, nil
+		}
+		var pe *permanentError
+		if errors.As(err, &pe) && err == error(pe) {
+			err = pe.err
+		}
+		if o.joinErrors {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs[:0], err)
+		}
+
+		retryAfter, hasRetryAfter := RetryAfterDelay(err)
+		if pe != nil || (!hasRetryAfter && o.shouldRetry != nil && !o.shouldRetry(err)) {
+			return giveUp(nil)
+		}
+		if o.maxAttempts > 0 && attempt >= o.maxAttempts {
+			return giveUp(nil)
+		}
+
+		if hasRetryAfter {
+			delay = retryAfter
+		} else {
+			delay = o.backoff.Next(attempt, delay)
+		}
+		elapsed := time.Since(start)
+		if o.maxElapsedTime > 0 && elapsed+delay > o.maxElapsedTime {
+			return giveUp(nil)
+		}
+		o.onRetry(RetryEvent{Attempt: attempt, Elapsed: elapsed, NextDelay: delay, Err: err})
+		if err := sleepContext(ctx, delay); err != nil {
+			return giveUp(err)
+		}
+	}
+}
+
+// giveUp 组装放弃重试时返回的错误，cause 为 ctx 结束或熔断等提前终止的原因
+func (o *retryOptions) giveUp(errs []error, cause error) error {
+	var last error
+	if len(errs) > 0 {
+		last = errs[len(errs)-1]
+	}
+	switch {
+	case last == nil:
+		return cause
+	case o.joinErrors && cause != nil:
+		return errors.Join(append([]error{cause}, errs...)...)
+	case o.joinErrors:
+		return errors.Join(errs...)
+	case cause != nil:
+		return fmt.Errorf("%w (最后一次错误: %w)", cause, last)
+	default:
+		return last
+	}
+}
+
+// sleepContext 等待 d 或 ctx 结束
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retry 函数接受一个要执行的操作（operation），最大尝试次数（maxRetries）和两次尝试之间的等待时间（delay）
+// 失败信息不再打印到标准输出，可通过 opts 传入 WithOnRetry、WithSlog 等钩子观测
+//
+// Deprecated: Retry 会吞掉最终的错误且无法取消，请使用 RetryWithContext
+func Retry(operation func() error, maxRetries int, delay time.Duration, opts ...Option) {
+	if maxRetries <= 0 {
+		return
 	}
+	opts = append([]Option{WithMaxAttempts(maxRetries), WithBackoff(ConstantBackoff(delay))}, opts...)
+	_ = RetryWithContext(context.Background(), func(context.Context) error {
+		return operation()
+	}, opts...)
 }
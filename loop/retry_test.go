@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryWithContext(t *testing.T) {
+	errTemp := errors.New("temporary")
+
+	t.Run("TC01: 重试后成功", func(t *testing.T) {
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errTemp
+			}
+			return nil
+		}, WithMaxAttempts(5), WithBackoff(ConstantBackoff(time.Millisecond)))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("TC02: 达到最大次数返回最后一次错误", func(t *testing.T) {
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errTemp
+		}, WithMaxAttempts(3), WithBackoff(ConstantBackoff(0)))
+		assert.ErrorIs(t, err, errTemp)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("TC03: 合并全部错误", func(t *testing.T) {
+		errs := []error{errors.New("e1"), errors.New("e2")}
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errs[calls-1]
+		}, WithMaxAttempts(2), WithBackoff(ConstantBackoff(0)), WithJoinErrors())
+		assert.ErrorIs(t, err, errs[0])
+		assert.ErrorIs(t, err, errs[1])
+	})
+
+	t.Run("TC04: 等待期间取消", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		err := RetryWithContext(ctx, func(ctx context.Context) error {
+			return errTemp
+		}, WithMaxAttempts(0), WithBackoff(ConstantBackoff(time.Hour)))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, errTemp)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("TC05: 超出最长耗时", func(t *testing.T) {
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errTemp
+		}, WithMaxAttempts(0), WithBackoff(ConstantBackoff(20*time.Millisecond)), WithMaxElapsedTime(50*time.Millisecond))
+		assert.ErrorIs(t, err, errTemp)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("TC06: 已取消的context不执行操作", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls := 0
+		err := RetryWithContext(ctx, func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, calls)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("线性", func(t *testing.T) {
+		b := LinearBackoff(100*time.Millisecond, 50*time.Millisecond, 180*time.Millisecond)
+		assert.Equal(t, 100*time.Millisecond, b.Next(1, 0))
+		assert.Equal(t, 150*time.Millisecond, b.Next(2, 0))
+		assert.Equal(t, 180*time.Millisecond, b.Next(3, 0))
+	})
+
+	t.Run("指数", func(t *testing.T) {
+		b := ExponentialBackoff(100*time.Millisecond, time.Second, NoJitter)
+		assert.Equal(t, 100*time.Millisecond, b.Next(1, 0))
+		assert.Equal(t, 400*time.Millisecond, b.Next(3, 0))
+		assert.Equal(t, time.Second, b.Next(10, 0))
+		assert.Equal(t, time.Second, b.Next(1000, 0))
+	})
+
+	t.Run("抖动范围", func(t *testing.T) {
+		full := ExponentialBackoff(100*time.Millisecond, time.Second, FullJitter)
+		equal := ExponentialBackoff(100*time.Millisecond, time.Second, EqualJitter)
+		decorrelated := ExponentialBackoff(100*time.Millisecond, time.Second, DecorrelatedJitter)
+		prev := time.Duration(0)
+		for i := 1; i <= 50; i++ {
+			d := full.Next(3, 0)
+			assert.True(t, d >= 0 && d <= 400*time.Millisecond, d)
+			d = equal.Next(3, 0)
+			assert.True(t, d >= 200*time.Millisecond && d <= 400*time.Millisecond, d)
+			prev = decorrelated.Next(i, prev)
+			assert.True(t, prev >= 100*time.Millisecond && prev <= time.Second, prev)
+		}
+	})
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryClassification(t *testing.T) {
+	errBad := errors.New("bad request")
+
+	t.Run("TC01: 永久错误立即停止", func(t *testing.T) {
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			return Permanent(errBad)
+		}, WithMaxAttempts(5), WithBackoff(ConstantBackoff(0)))
+		assert.Equal(t, errBad, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("TC02: 分类函数拒绝重试", func(t *testing.T) {
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			return StatusError(400, errBad)
+		}, WithMaxAttempts(5), WithBackoff(ConstantBackoff(0)), WithShouldRetry(IsRetryableHTTPStatus))
+		assert.ErrorIs(t, err, errBad)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("TC03: 服务端指定等待时间", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return RetryAfter(StatusError(429, nil), 30*time.Millisecond)
+			}
+			return nil
+		}, WithBackoff(ConstantBackoff(0)), WithShouldRetry(func(error) bool { return false }))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	})
+
+	t.Run("TC04: 内置分类函数", func(t *testing.T) {
+		classify := AnyOf(IsTimeout, IsDeadlineExceeded, IsRetryableHTTPStatus)
+		assert.True(t, classify(timeoutError{}))
+		assert.True(t, classify(context.DeadlineExceeded))
+		assert.True(t, classify(StatusError(429, nil)))
+		assert.True(t, classify(StatusError(503, nil)))
+		assert.False(t, classify(StatusError(501, nil)))
+		assert.False(t, classify(StatusError(404, nil)))
+		assert.False(t, classify(errBad))
+	})
+}
+
+func TestRetryHooks(t *testing.T) {
+	errTemp := errors.New("temporary")
+
+	t.Run("TC01: 钩子事件", func(t *testing.T) {
+		var retries, successes []RetryEvent
+		calls := 0
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errTemp
+			}
+			return nil
+		}, WithBackoff(ConstantBackoff(time.Millisecond)),
+			WithOnRetry(func(e RetryEvent) { retries = append(retries, e) }),
+			WithOnSuccess(func(e RetryEvent) { successes = append(successes, e) }),
+			WithOnGiveUp(func(e RetryEvent) { t.Errorf("unexpected give up: %v", e.Err) }))
+		assert.NoError(t, err)
+		assert.Len(t, retries, 2)
+		assert.Equal(t, 1, retries[0].Attempt)
+		assert.Equal(t, time.Millisecond, retries[0].NextDelay)
+		assert.ErrorIs(t, retries[1].Err, errTemp)
+		assert.Len(t, successes, 1)
+		assert.Equal(t, 3, successes[0].Attempt)
+	})
+
+	t.Run("TC02: slog 输出", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		err := RetryWithContext(context.Background(), func(ctx context.Context) error {
+			return errTemp
+		}, WithMaxAttempts(2), WithBackoff(ConstantBackoff(0)), WithSlog(logger))
+		assert.ErrorIs(t, err, errTemp)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"msg":"retry attempt failed"`)
+		assert.Contains(t, lines[0], `"attempt":1`)
+		assert.Contains(t, lines[1], `"msg":"retry gave up"`)
+		assert.Contains(t, lines[1], `"error":"temporary"`)
+	})
+
+	t.Run("TC03: Retry 不再打印并支持钩子", func(t *testing.T) {
+		attempts := 0
+		Retry(func() error { return errTemp }, 3, 0, WithOnGiveUp(func(e RetryEvent) { attempts = e.Attempt }))
+		assert.Equal(t, 3, attempts)
+	})
+}
+
+func TestRetryValue(t *testing.T) {
+	errTemp := errors.New("temporary")
+
+	t.Run("TC01: 返回成功的值和统计", func(t *testing.T) {
+		var stats Stats
+		calls := 0
+		v, err := RetryValue(context.Background(), func(ctx context.Context) (string, error) {
+			calls++
+			if calls < 2 {
+				return "partial", errTemp
+			}
+			return "ok", nil
+		}, WithBackoff(ConstantBackoff(time.Millisecond)), WithStats(&stats))
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", v)
+		assert.Equal(t, 2, stats.Attempts)
+		assert.GreaterOrEqual(t, stats.Elapsed, time.Millisecond)
+	})
+
+	t.Run("TC02: 失败返回零值", func(t *testing.T) {
+		var stats Stats
+		v, err := RetryValue(context.Background(), func(ctx context.Context) (*int, error) {
+			n := 1
+			return &n, Permanent(errTemp)
+		}, WithStats(&stats))
+		assert.ErrorIs(t, err, errTemp)
+		assert.Nil(t, v)
+		assert.Equal(t, 1, stats.Attempts)
+	})
+}
@@ -0,0 +1,396 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	loop "github.com/hargeek/gopkg/loop"
+)
+
+// DefaultClientTimeout Client 默认的单次请求超时
+const DefaultClientTimeout = 30 * time.Second
+
+// DefaultMaxRetryAfter 重试时允许等待的最长 Retry-After，超过时不再重试而是直接返回错误
+const DefaultMaxRetryAfter = time.Minute
+
+// maxErrorBodySnippet HTTPError 中保留的响应体最大长度
+const maxErrorBodySnippet = 4 << 10
+
+// HTTPError 非 2xx/3xx 响应对应的错误，实现了 loop.StatusCoder，可直接配合 loop.IsRetryableHTTPStatus 使用
+type HTTPError struct {
+	Method string
+	URL    string
+	Code   int    // HTTP 状态码
+	Status string // 如 "404 Not Found"
+	Header http.Header
+	Body   string // 响应体片段，最多 4KB
+}
+
+// Error 实现 error 接口
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// StatusCode 返回 HTTP 状态码
+func (e *HTTPError) StatusCode() int { return e.Code }
+
+// Client 可复用的 HTTP 客户端封装：基础地址、默认请求头、单次请求超时、幂等请求自动重试和 JSON 辅助方法，可并发使用
+type Client struct {
+	baseURL       *url.URL
+	header        http.Header
+	timeout       time.Duration
+	httpClient    *http.Client
+	retry         bool
+	retryOpts     []loop.Option
+	maxRetryAfter time.Duration
+	middlewares   []TransportMiddleware
+	ssrfGuard     *SSRFGuard
+}
+
+// ClientOption Client 的配置项
+type ClientOption func(*Client) error
+
+// WithBaseURL 设置基础地址，请求路径会拼接在其路径之后
+func WithBaseURL(rawURL string) ClientOption {
+	return func(c *Client) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("无效的基础地址: %w", err)
+		}
+		c.baseURL = u
+		return nil
+	}
+}
+
+// WithHeader 添加默认请求头
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) error {
+		c.header.Add(key, value)
+		return nil
+	}
+}
+
+// WithTimeout 设置单次请求超时（开启重试时为每次尝试的超时），<=0 表示不设超时，默认 DefaultClientTimeout
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.timeout = d
+		return nil
+	}
+}
+
+// WithHTTPClient 使用自定义的 http.Client（如自定义 Transport），其 Timeout 会与 WithTimeout 同时生效
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithRetry 对幂等方法（GET、HEAD、OPTIONS、TRACE、PUT、DELETE）开启自动重试，opts 为 loop 包的重试配置；
+// 默认只重试网络错误和 429/5xx 响应，这些响应带 Retry-After 时按其等待（上限见 WithMaxRetryAfter）
+func WithRetry(opts ...loop.Option) ClientOption {
+	return func(c *Client) error {
+		c.retry = true
+		c.retryOpts = append([]loop.Option{loop.WithShouldRetry(shouldRetryRequest)}, opts...)
+		return nil
+	}
+}
+
+// WithMaxRetryAfter 设置重试时允许等待的最长 Retry-After，超过时直接返回错误，<=0 表示不限制，默认 DefaultMaxRetryAfter；
+// loop.WithMaxElapsedTime 同样会限制 Retry-After 的等待
+func WithMaxRetryAfter(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.maxRetryAfter = d
+		return nil
+	}
+}
+
+// NewClient 创建客户端
+func NewClient(opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		header:        make(http.Header),
+		timeout:       DefaultClientTimeout,
+		maxRetryAfter: DefaultMaxRetryAfter,
+		httpClient:    &http.Client{},
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.ssrfGuard != nil {
+		hc := *c.httpClient
+		hc.Transport = c.ssrfGuard.Transport()
+		c.httpClient = &hc
+	}
+	if len(c.middlewares) > 0 {
+		hc := *c.httpClient
+		hc.Transport = ChainTransport(hc.Transport, c.middlewares...)
+		c.httpClient = &hc
+	}
+	return c, nil
+}
+
+// Request 一次请求的描述
+type Request struct {
+	Method  string
+	Path    string        // 相对于基础地址的路径，也可以是完整的 URL，可以带查询参数
+	Query   interface{}   // 查询参数：url.Values 或可被 ConvertToQueryParams 转换的结构体
+	Header  http.Header   // 额外的请求头，会覆盖同名的默认请求头
+	Body    []byte        // 原始请求体，与 JSON 互斥
+	JSON    interface{}   // 编码为 JSON 的请求体
+	Timeout time.Duration // 覆盖客户端的单次请求超时
+}
+
+// Do 发送请求，状态码 >= 400 时关闭响应体并返回 *HTTPError；成功时调用方负责关闭响应体
+func (c *Client) Do(ctx context.Context, r *Request) (*http.Response, error) {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	target, err := c.buildURL(r.Path, r.Query)
+	if err != nil {
+		return nil, err
+	}
+	body, contentType, err := r.encodeBody()
+	if err != nil {
+		return nil, err
+	}
+	timeout := c.timeout
+	if r.Timeout != 0 {
+		timeout = r.Timeout
+	}
+
+	attempt := func(ctx context.Context) (*http.Response, error) {
+		cancel := context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reader)
+		if err != nil {
+			cancel()
+			return nil, loop.Permanent(err)
+		}
+		for k, vs := range c.header {
+			req.Header[k] = append([]string(nil), vs...)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for k, vs := range r.Header {
+			req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), vs...)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			herr := newHTTPError(req, resp)
+			cancel()
+			return nil, herr
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+
+	if !c.retry || !isIdempotent(method) {
+		return attempt(ctx)
+	}
+	resp, err := loop.RetryValue(ctx, func(ctx context.Context) (*http.Response, error) {
+		resp, err := attempt(ctx)
+		var herr *HTTPError
+		if errors.As(err, &herr) {
+			return nil, withRetryAfter(err, herr.Code, herr.Header, c.maxRetryAfter)
+		}
+		return resp, err
+	}, c.retryOpts...)
+	return resp, err
+}
+
+// DoJSON 发送请求并将 JSON 响应解码到 out（可为 nil），204 或空响应体时不解码
+func (c *Client) DoJSON(ctx context.Context, r *Request, out interface{}) error {
+	if r.Header == nil || r.Header.Get("Accept") == "" {
+		header := r.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("Accept", "application/json")
+		copied := *r
+		copied.Header = header
+		r = &copied
+	}
+	resp, err := c.Do(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// GetJSON 发送 GET 请求并将 JSON 响应解码为 T，query 为 url.Values 或结构体（可为 nil）
+func GetJSON[T any](ctx context.Context, c *Client, path string, query interface{}) (T, error) {
+	var out T
+	err := c.DoJSON(ctx, &Request{Method: http.MethodGet, Path: path, Query: query}, &out)
+	return out, err
+}
+
+// PostJSON 以 JSON 编码 body 发送 POST 请求并将 JSON 响应解码为 T
+func PostJSON[T any](ctx context.Context, c *Client, path string, body interface{}) (T, error) {
+	var out T
+	err := c.DoJSON(ctx, &Request{Method: http.MethodPost, Path: path, JSON: body}, &out)
+	return out, err
+}
+
+// buildURL 拼接基础地址、路径和查询参数
+func (c *Client) buildURL(path string, query interface{}) (string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("无效的请求路径: %w", err)
+	}
+	u := ref
+	if !ref.IsAbs() && c.baseURL != nil {
+		u = c.baseURL.JoinPath(ref.Path)
+		if strings.HasSuffix(ref.Path, "/") && !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		u.RawQuery = ref.RawQuery
+	}
+
+	if query != nil {
+		var extra url.Values
+		switch q := query.(type) {
+		case url.Values:
+			extra = q
+		default:
+			if extra, err = ConvertToQueryParams(query); err != nil {
+				return "", err
+			}
+		}
+		values := u.Query()
+		for k, vs := range extra {
+			values[k] = append(values[k], vs...)
+		}
+		u.RawQuery = values.Encode()
+	}
+	return u.String(), nil
+}
+
+// encodeBody 返回请求体和 Content-Type
+func (r *Request) encodeBody() ([]byte, string, error) {
+	if r.JSON != nil {
+		if r.Body != nil {
+			return nil, "", errors.New("Body 与 JSON 不能同时设置")
+		}
+		b, err := json.Marshal(r.JSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("编码请求体失败: %w", err)
+		}
+		return b, "application/json", nil
+	}
+	return r.Body, "", nil
+}
+
+// newHTTPError 读取响应体片段并关闭响应
+func newHTTPError(req *http.Request, resp *http.Response) *HTTPError {
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySnippet))
+	return &HTTPError{
+		Method: req.Method,
+		URL:    req.URL.Redacted(),
+		Code:   resp.StatusCode,
+		Status: resp.Status,
+		Header: resp.Header,
+		Body:   strings.TrimSpace(string(snippet)),
+	}
+}
+
+// shouldRetryRequest 默认的重试判断：429/5xx 响应和网络错误重试，其他 HTTP 错误不重试
+func shouldRetryRequest(err error) bool {
+	var herr *HTTPError
+	if errors.As(err, &herr) {
+		return loop.IsRetryableStatusCode(herr.Code)
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// isIdempotent 判断方法是否幂等
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// withRetryAfter 为可重试状态码（429/5xx）的错误附加 Retry-After 等待时间；其他状态码原样返回，交给重试判断处理；
+// 等待时间超过 max（>0）时返回 loop.Permanent 停止重试
+func withRetryAfter(err error, code int, header http.Header, max time.Duration) error {
+	if !loop.IsRetryableStatusCode(code) {
+		return err
+	}
+	d, ok := parseRetryAfter(header.Get("Retry-After"))
+	if !ok {
+		return err
+	}
+	if max > 0 && d > max {
+		return loop.Permanent(err)
+	}
+	return loop.RetryAfter(err, d)
+}
+
+// parseRetryAfter 解析 Retry-After 头（秒数或 HTTP 日期）
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// cancelOnClose 关闭响应体时释放单次请求的 context
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
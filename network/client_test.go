@@ -0,0 +1,125 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loop "github.com/hargeek/gopkg/loop"
+	"github.com/stretchr/testify/assert"
+)
+
+type clientUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestClient(t *testing.T) {
+	var failures atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users":
+			assert.Equal(t, "token", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", r.Header.Get("Accept"))
+			assert.Equal(t, "2", r.URL.Query().Get("page"))
+			_ = json.NewEncoder(w).Encode([]clientUser{{ID: 1, Name: "alice"}})
+		case "/api/users/create":
+			var u clientUser
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&u))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			u.ID = 42
+			_ = json.NewEncoder(w).Encode(u)
+		case "/api/flaky":
+			if failures.Add(1) <= 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":7}`))
+		case "/api/bad":
+			failures.Add(1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid name"}`))
+		case "/api/busy":
+			failures.Add(1)
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/api/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		WithBaseURL(server.URL+"/api"),
+		WithHeader("Authorization", "token"),
+		WithRetry(loop.WithMaxAttempts(3), loop.WithBackoff(loop.ConstantBackoff(0))),
+	)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("TC01: GetJSON 与结构体查询参数", func(t *testing.T) {
+		type query struct {
+			Page int `form:"page"`
+		}
+		users, err := GetJSON[[]clientUser](ctx, client, "users", query{Page: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []clientUser{{ID: 1, Name: "alice"}}, users)
+	})
+
+	t.Run("TC02: PostJSON", func(t *testing.T) {
+		u, err := PostJSON[clientUser](ctx, client, "/users/create", clientUser{Name: "bob"})
+		assert.NoError(t, err)
+		assert.Equal(t, clientUser{ID: 42, Name: "bob"}, u)
+	})
+
+	t.Run("TC03: 幂等请求自动重试", func(t *testing.T) {
+		failures.Store(0)
+		u, err := GetJSON[clientUser](ctx, client, "flaky", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, u.ID)
+		assert.Equal(t, int32(3), failures.Load())
+	})
+
+	t.Run("TC04: 4xx 返回类型化错误且不重试", func(t *testing.T) {
+		failures.Store(0)
+		_, err := GetJSON[clientUser](ctx, client, "bad", nil)
+		var herr *HTTPError
+		assert.True(t, errors.As(err, &herr))
+		assert.Equal(t, http.StatusBadRequest, herr.StatusCode())
+		assert.Equal(t, `{"error":"invalid name"}`, herr.Body)
+		assert.False(t, loop.IsRetryableHTTPStatus(err))
+		assert.Equal(t, int32(1), failures.Load())
+	})
+
+	t.Run("TC05: Retry-After 超过上限时不再等待", func(t *testing.T) {
+		failures.Store(0)
+		start := time.Now()
+		_, err := GetJSON[clientUser](ctx, client, "busy", nil)
+		var herr *HTTPError
+		assert.True(t, errors.As(err, &herr))
+		assert.Equal(t, http.StatusServiceUnavailable, herr.Code)
+		assert.Equal(t, int32(1), failures.Load())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("TC06: 单次请求超时", func(t *testing.T) {
+		err := client.DoJSON(ctx, &Request{Method: http.MethodPost, Path: "slow", Timeout: 20 * time.Millisecond}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("TC07: 无效配置", func(t *testing.T) {
+		_, err := NewClient(WithBaseURL("://bad"))
+		assert.Error(t, err)
+		err = client.DoJSON(ctx, &Request{Body: []byte("x"), JSON: 1}, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package gonic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+)
+
+// IPAccessList IP 黑白名单：命中 deny 的地址拒绝；allow 非空时只放行命中 allow 的地址
+// 名单可在运行时整体替换（Update、LoadFile、WatchFile），可并发使用
+type IPAccessList struct {
+	rules atomic.Pointer[accessRules]
+}
+
+type accessRules struct {
+	allow, deny *network.PrefixSet
+}
+
+// NewIPAccessList 创建黑白名单，allow、deny 为 CIDR 或单个 IP
+func NewIPAccessList(allow, deny []string) (*IPAccessList, error) {
+	l := &IPAccessList{}
+	if err := l.Update(allow, deny); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Update 整体替换名单，解析失败时保留原名单
+func (l *IPAccessList) Update(allow, deny []string) error {
+	allowSet, err := network.ParsePrefixSet(allow)
+	if err != nil {
+		return fmt.Errorf("解析白名单失败: %w", err)
+	}
+	denySet, err := network.ParsePrefixSet(deny)
+	if err != nil {
+		return fmt.Errorf("解析黑名单失败: %w", err)
+	}
+	l.rules.Store(&accessRules{allow: allowSet, deny: denySet})
+	return nil
+}
+
+// LoadFile 从文件加载名单，每行一条规则，格式为 "allow <CIDR|IP>" 或 "deny <CIDR|IP>"，忽略空行和 # 开头的注释
+func (l *IPAccessList) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var allow, deny []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		action, value, _ := strings.Cut(line, " ")
+		switch strings.ToLower(action) {
+		case "allow":
+			allow = append(allow, strings.TrimSpace(value))
+		case "deny":
+			deny = append(deny, strings.TrimSpace(value))
+		default:
+			return fmt.Errorf("%s:%d: 无效的规则 %q", path, lineNo, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return l.Update(allow, deny)
+}
+
+// WatchFile 加载文件并每隔 interval 检查修改时间，变化时热加载，直到 ctx 结束
+// 首次加载失败直接返回错误；之后的加载错误交给 onError（可为 nil），并保留原名单
+func (l *IPAccessList) WatchFile(ctx context.Context, path string, interval time.Duration, onError func(error)) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := l.LoadFile(path); err != nil {
+		return err
+	}
+
+	go func() {
+		modTime, size := info.ModTime(), info.Size()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err == nil && info.ModTime().Equal(modTime) && info.Size() == size {
+				continue
+			}
+			if err == nil {
+				modTime, size = info.ModTime(), info.Size()
+				err = l.LoadFile(path)
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Allowed 判断地址是否允许访问
+func (l *IPAccessList) Allowed(addr netip.Addr) bool {
+	rules := l.rules.Load()
+	if rules.deny.Contains(addr) {
+		return false
+	}
+	return rules.allow.Len() == 0 || rules.allow.Contains(addr)
+}
+
+// IPAccessControl 按黑白名单限制访问的中间件，客户端地址取自 ClientIPMiddleware 的解析结果，未配置时为直连对端地址，
+// 从不读取 X-Forwarded-For 等客户端可伪造的请求头；位于反向代理之后时必须先使用 ClientIPMiddleware。地址无法解析或不允许访问时返回 403
+func IPAccessControl(list *IPAccessList) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var addr netip.Addr
+		if ip, ok := ResolvedClientIP(c); ok {
+			addr = ip.Addr
+		} else if parsed, err := network.ParseIP(c.Request.RemoteAddr); err == nil {
+			addr = parsed
+		}
+		if !addr.IsValid() || !list.Allowed(addr) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": "access denied",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,245 @@
+package gonic
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+	gtime "github.com/hargeek/gopkg/time"
+)
+
+// combinedTimeFormat combined 日志格式中的时间格式
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogOption AccessLog 中间件的配置项
+type AccessLogOption func(*accessLogConfig)
+
+type accessLogConfig struct {
+	logger     *slog.Logger
+	combined   io.Writer
+	sampleRate float64
+	skipPaths  map[string]bool
+	redact     map[string]bool
+	normalizer *network.PathNormalizer
+}
+
+// WithAccessLogger 设置输出的 slog.Logger，默认 slog.Default()
+func WithAccessLogger(logger *slog.Logger) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		cfg.logger = logger
+	}
+}
+
+// WithCombinedLogFormat 改为向 w 输出 Apache/NGINX combined 格式的日志，不再输出 slog 记录
+func WithCombinedLogFormat(w io.Writer) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		cfg.combined = w
+	}
+}
+
+// WithAccessLogSampleRate 设置采样率（0~1），状态码 >= 500 的请求总是记录，默认 1 即全部记录
+func WithAccessLogSampleRate(rate float64) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		cfg.sampleRate = rate
+	}
+}
+
+// WithAccessLogSkipPaths 不记录的请求路径（精确匹配），如健康检查 "/healthz"
+func WithAccessLogSkipPaths(paths ...string) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		for _, p := range paths {
+			cfg.skipPaths[p] = true
+		}
+	}
+}
+
+// WithAccessLogRedactQuery 记录时将这些查询参数的值替换为 "REDACTED"，如 token、signature
+func WithAccessLogRedactQuery(params ...string) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		for _, p := range params {
+			cfg.redact[p] = true
+		}
+	}
+}
+
+// WithAccessLogPathNormalizer 未匹配到 gin 路由时使用 n 标准化路径，默认使用 network.NormalizePath
+func WithAccessLogPathNormalizer(n *network.PathNormalizer) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		cfg.normalizer = n
+	}
+}
+
+// AccessLog 访问日志中间件，记录方法、路由、状态码、耗时、响应字节数、客户端IP（GetClientIP）、请求ID 和 User-Agent。
+// 路由优先使用 gin 的 FullPath（如 /user/:id），未匹配路由时使用标准化后的请求路径；
+// 请求ID 取自 context（network.RequestIDFromContext）或 X-Request-ID 请求头、响应头
+func AccessLog(opts ...AccessLogOption) gin.HandlerFunc {
+	cfg := &accessLogConfig{
+		sampleRate: 1,
+		skipPaths:  make(map[string]bool),
+		redact:     make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.logger == nil {
+		cfg.logger = slog.Default()
+	}
+	var mu sync.Mutex
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if cfg.skipPaths[path] {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+		end := time.Now()
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError && cfg.sampleRate < 1 && rand.Float64() >= cfg.sampleRate {
+			return
+		}
+
+		// 使用转义后的路径，避免 %0A、%22 等解码后伪造日志行
+		target := c.Request.URL.EscapedPath()
+		if query := redactQuery(c.Request.URL.RawQuery, cfg.redact); query != "" {
+			target += "?" + query
+		}
+
+		if cfg.combined != nil {
+			line := formatCombined(c, start, target, status)
+			mu.Lock()
+			_, _ = io.WriteString(cfg.combined, line)
+			mu.Unlock()
+			return
+		}
+
+		latency := gtime.ParseHumanTimeCost(start, end)
+		if latency == "" {
+			latency = end.Sub(start).String()
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", accessRoute(c, cfg.normalizer)),
+			slog.String("path", target),
+			slog.Int("status", status),
+			slog.String("latency", latency),
+			slog.Duration("duration", end.Sub(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", GetClientIP(c)),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if id := accessRequestID(c); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		cfg.logger.LogAttrs(c.Request.Context(), level, "http access", attrs...)
+	}
+}
+
+// accessRoute 返回用于日志的路由
+func accessRoute(c *gin.Context, normalizer *network.PathNormalizer) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	if normalizer != nil {
+		return normalizer.Normalize(c.Request.URL.Path)
+	}
+	return network.NormalizePath(c.Request.URL.Path)
+}
+
+// accessRequestID 返回请求ID
+func accessRequestID(c *gin.Context) string {
+	if id := network.RequestIDFromContext(c.Request.Context()); id != "" {
+		return id
+	}
+	if id := c.GetHeader(network.RequestIDHeader); id != "" {
+		return id
+	}
+	return c.Writer.Header().Get(network.RequestIDHeader)
+}
+
+// redactQuery 替换需要脱敏的查询参数值，没有需要脱敏的参数时原样返回
+func redactQuery(rawQuery string, redact map[string]bool) string {
+	if rawQuery == "" || len(redact) == 0 {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	changed := false
+	for k, vs := range values {
+		if redact[k] {
+			for i := range vs {
+				vs[i] = "REDACTED"
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+// formatCombined 生成一行 combined 格式的日志：
+// client - user [time] "METHOD target PROTO" status bytes "referer" "user-agent"，来自客户端的字段均经过 combinedEscape 转义
+func formatCombined(c *gin.Context, start time.Time, target string, status int) string {
+	user := "-"
+	if u, _, ok := c.Request.BasicAuth(); ok && u != "" {
+		user = combinedEscape(u)
+	}
+	size := "-"
+	if n := c.Writer.Size(); n > 0 {
+		size = fmt.Sprint(n)
+	}
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s \"%s\" \"%s\"\n",
+		GetClientIP(c),
+		user,
+		start.Format(combinedTimeFormat),
+		combinedEscape(c.Request.Method),
+		combinedEscape(target),
+		combinedEscape(c.Request.Proto),
+		status,
+		size,
+		combinedEscape(c.Request.Referer()),
+		combinedEscape(c.Request.UserAgent()),
+	)
+}
+
+// combinedEscape 与 nginx 相同，将引号、反斜杠、控制字符和非 ASCII 字节转义为 \xHH，避免伪造日志行；空值输出 "-"
+func combinedEscape(s string) string {
+	if s == "" {
+		return "-"
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' || c < 0x20 || c >= 0x7f {
+			fmt.Fprintf(&sb, `\x%02X`, c)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
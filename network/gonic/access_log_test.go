@@ -0,0 +1,111 @@
+package gonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	handler := func(c *gin.Context) { c.String(http.StatusOK, "hello") }
+
+	t.Run("TC01: slog 记录", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := gin.New()
+		r.Use(AccessLog(
+			WithAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+			WithAccessLogSkipPaths("/healthz"),
+			WithAccessLogRedactQuery("token"),
+		))
+		r.GET("/users/:id", handler)
+		r.GET("/healthz", handler)
+
+		serveFrom(r, http.MethodGet, "/users/42?token=secret&page=1", "10.0.0.1:1000", map[string]string{
+			"X-Real-Ip":             "6.6.6.6",
+			"User-Agent":            "test-agent",
+			network.RequestIDHeader: "req-1",
+		})
+		serve(r, http.MethodGet, "/healthz", nil)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 1)
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "GET", record["method"])
+		assert.Equal(t, "/users/:id", record["route"])
+		assert.Equal(t, "/users/42?page=1&token=REDACTED", record["path"])
+		assert.Equal(t, float64(200), record["status"])
+		assert.Equal(t, float64(5), record["bytes"])
+		assert.Equal(t, "10.0.0.1", record["client_ip"])
+		assert.Equal(t, "test-agent", record["user_agent"])
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.NotEmpty(t, record["latency"])
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("TC02: 未匹配路由与日志级别", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := gin.New()
+		r.Use(AccessLog(
+			WithAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+			WithAccessLogPathNormalizer(network.NewPathNormalizer()),
+		))
+		serve(r, http.MethodGet, "/missing/7/items/8", nil)
+
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "/missing/:id/items/:id", record["route"])
+	})
+
+	t.Run("TC03: combined 格式", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := gin.New()
+		r.Use(AccessLog(WithCombinedLogFormat(&buf)))
+		r.GET("/users/:id", handler)
+
+		req := map[string]string{"Referer": "https://a.com/", "User-Agent": `curl/8 "x"`}
+		serveFrom(r, http.MethodGet, "/users/1?q=go", "10.0.0.2:1000", req)
+		pattern := `^10\.0\.0\.2 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /users/1\?q=go HTTP/1\.1" 200 5 "https://a\.com/" "curl/8 \\x22x\\x22"\n$`
+		assert.Regexp(t, regexp.MustCompile(pattern), buf.String())
+	})
+
+	t.Run("TC04: combined 格式不能伪造日志行", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := gin.New()
+		r.Use(AccessLog(WithCombinedLogFormat(&buf)))
+
+		serve(r, http.MethodGet, "/x%0A6.6.6.6%20-%20-%20%5Bfake%5D%20%22GET%20/admin%22?q=\"a\"", map[string]string{
+			"Referer":    "https://a.com/\\\"",
+			"User-Agent": "ua\n6.6.6.6 - - [fake]",
+		})
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		assert.Len(t, lines, 1)
+		assert.Contains(t, lines[0], `"GET /x%0A6.6.6.6%20-%20-%20%5Bfake%5D%20%22GET%20/admin%22?q=\x22a\x22 HTTP/1.1"`)
+		assert.Contains(t, lines[0], `"https://a.com/\x5C\x22" "ua\x0A6.6.6.6 - - [fake]"`)
+	})
+
+	t.Run("TC05: 采样", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := gin.New()
+		r.Use(AccessLog(WithCombinedLogFormat(&buf), WithAccessLogSampleRate(0)))
+		r.GET("/ok", handler)
+		r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+		for i := 0; i < 10; i++ {
+			serve(r, http.MethodGet, "/ok", nil)
+		}
+		serve(r, http.MethodGet, "/fail", nil)
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		assert.Contains(t, buf.String(), `"GET /fail HTTP/1.1" 500 -`)
+	})
+}
@@ -0,0 +1,82 @@
+package gonic
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPAccessControl(t *testing.T) {
+	list, err := NewIPAccessList([]string{"10.0.0.0/8"}, []string{"10.0.0.66"})
+	assert.NoError(t, err)
+
+	r := gin.New()
+	r.Use(IPAccessControl(list))
+	r.GET("/admin", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	code := func(remoteAddr string) int {
+		return serveFrom(r, http.MethodGet, "/admin", remoteAddr, nil).Code
+	}
+	assert.Equal(t, http.StatusOK, code("10.1.2.3:1000"))
+	assert.Equal(t, http.StatusOK, code("[::ffff:10.1.2.3]:1000"))
+	assert.Equal(t, http.StatusForbidden, code("10.0.0.66:1000"))
+	assert.Equal(t, http.StatusForbidden, code("8.8.8.8:1000"))
+	assert.Equal(t, http.StatusForbidden, code("not-an-ip"))
+
+	assert.Error(t, list.Update([]string{"bad"}, nil))
+	assert.Equal(t, http.StatusOK, code("10.1.2.3:1000"))
+}
+
+func TestIPAccessControlSpoofedHeader(t *testing.T) {
+	list, err := NewIPAccessList([]string{"10.0.0.0/8"}, nil)
+	assert.NoError(t, err)
+	spoofed := map[string]string{"X-Forwarded-For": "10.1.1.1", "X-Real-Ip": "10.1.1.1", "Remoteip": "10.1.1.1"}
+
+	t.Run("未配置解析器时忽略请求头", func(t *testing.T) {
+		r := gin.New()
+		r.Use(IPAccessControl(list))
+		r.GET("/admin", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+		assert.Equal(t, http.StatusForbidden, serveFrom(r, http.MethodGet, "/admin", "203.0.113.5:1000", spoofed).Code)
+	})
+
+	t.Run("直连对端不是可信代理时忽略XFF", func(t *testing.T) {
+		resolver, err := NewIPResolver([]string{"192.168.0.0/16"})
+		assert.NoError(t, err)
+		r := gin.New()
+		r.Use(ClientIPMiddleware(resolver), IPAccessControl(list))
+		r.GET("/admin", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+		assert.Equal(t, http.StatusForbidden, serveFrom(r, http.MethodGet, "/admin", "203.0.113.5:1000", spoofed).Code)
+		assert.Equal(t, http.StatusOK, serveFrom(r, http.MethodGet, "/admin", "192.168.0.1:1000", map[string]string{"X-Forwarded-For": "10.1.1.1"}).Code)
+	})
+}
+
+func mustAddr(s string) netip.Addr {
+	return netip.MustParseAddr(s)
+}
+
+func TestIPAccessListWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acl.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# admin\nallow 10.0.0.0/8\n"), 0o644))
+
+	list := &IPAccessList{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, list.WatchFile(ctx, path, 10*time.Millisecond, nil))
+	assert.True(t, list.Allowed(mustAddr("10.1.1.1")))
+	assert.False(t, list.Allowed(mustAddr("192.168.0.1")))
+
+	assert.NoError(t, os.WriteFile(path, []byte("allow 192.168.0.0/16\ndeny 192.168.0.2\n"), 0o644))
+	assert.Eventually(t, func() bool {
+		return list.Allowed(mustAddr("192.168.0.1")) && !list.Allowed(mustAddr("10.1.1.1"))
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, list.Allowed(mustAddr("192.168.0.2")))
+
+	assert.Error(t, (&IPAccessList{}).LoadFile(filepath.Join(t.TempDir(), "missing")))
+}
@@ -0,0 +1,224 @@
+package gonic
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	loop "github.com/hargeek/gopkg/loop"
+	"github.com/hargeek/gopkg/network"
+)
+
+// problemContentType RFC 7807 的 Content-Type
+const problemContentType = "application/problem+json"
+
+// Error 带 HTTP 状态码的业务错误，Message 会返回给客户端，Err 只用于日志
+type Error struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+// NewError 创建业务错误
+func NewError(status int, message string) *Error {
+	return &Error{Status: status, Message: message}
+}
+
+// WrapError 包装底层错误，message 返回给客户端，err 不会暴露
+func WrapError(status int, err error, message string) *Error {
+	return &Error{Status: status, Message: message, Err: err}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 返回底层错误
+func (e *Error) Unwrap() error { return e.Err }
+
+// StatusCode 实现 loop.StatusCoder
+func (e *Error) StatusCode() int { return e.Status }
+
+// ErrorOption Recovery、ErrorHandler 的配置项
+type ErrorOption func(*errorConfig)
+
+type errorConfig struct {
+	problem  bool
+	mappings []errorMapping
+	logger   *slog.Logger
+}
+
+type errorMapping struct {
+	target error
+	status int
+}
+
+// WithProblemJSON 使用 RFC 7807 application/problem+json 格式响应，默认 {"code":...,"message":...}
+func WithProblemJSON() ErrorOption {
+	return func(cfg *errorConfig) {
+		cfg.problem = true
+	}
+}
+
+// WithErrorStatus 将 errors.Is(err, target) 的错误映射为 status，如 WithErrorStatus(sql.ErrNoRows, 404)，按注册顺序匹配
+func WithErrorStatus(target error, status int) ErrorOption {
+	return func(cfg *errorConfig) {
+		cfg.mappings = append(cfg.mappings, errorMapping{target: target, status: status})
+	}
+}
+
+// WithErrorLogger 设置记录 panic 和 5xx 错误的 logger，默认 slog.Default()
+func WithErrorLogger(logger *slog.Logger) ErrorOption {
+	return func(cfg *errorConfig) {
+		cfg.logger = logger
+	}
+}
+
+func newErrorConfig(opts []ErrorOption) *errorConfig {
+	cfg := &errorConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.logger == nil {
+		cfg.logger = slog.Default()
+	}
+	return cfg
+}
+
+// ErrorStatus 返回错误对应的状态码：*Error 或实现 loop.StatusCoder 的错误使用其状态码（*Error 的状态码不在 400-599 时为 500），
+// 调用上游服务失败的 *network.HTTPError 为 502，context.DeadlineExceeded 为 504，其他为 500
+func ErrorStatus(err error) int {
+	var (
+		e    *Error
+		herr *network.HTTPError
+		sc   loop.StatusCoder
+	)
+	switch {
+	case errors.As(err, &e):
+		return errorStatusCode(e.Status)
+	case errors.As(err, &herr):
+		return http.StatusBadGateway
+	case errors.As(err, &sc) && isErrorStatus(sc.StatusCode()):
+		return sc.StatusCode()
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// isErrorStatus 判断是否为 4xx、5xx 状态码
+func isErrorStatus(status int) bool {
+	return status >= http.StatusBadRequest && status <= 599
+}
+
+// errorStatusCode 不在 400-599 范围内的状态码（如未设置的 0）按 500 处理，避免以成功状态返回错误
+func errorStatusCode(status int) int {
+	if !isErrorStatus(status) {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// status 先按 WithErrorStatus 注册的映射匹配，再使用 ErrorStatus
+func (cfg *errorConfig) status(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return errorStatusCode(e.Status)
+	}
+	for _, m := range cfg.mappings {
+		if errors.Is(err, m.target) {
+			return errorStatusCode(m.status)
+		}
+	}
+	return ErrorStatus(err)
+}
+
+// render 写入错误响应并中止请求；只有 *Error 的 Message 会返回给客户端，其他错误使用状态码的标准描述
+func (cfg *errorConfig) render(c *gin.Context, status int, err error) {
+	if c.Writer.Written() {
+		c.Abort()
+		return
+	}
+	status = errorStatusCode(status)
+	message := http.StatusText(status)
+	var e *Error
+	if errors.As(err, &e) && e.Message != "" {
+		message = e.Message
+	}
+	requestID := accessRequestID(c)
+
+	if cfg.problem {
+		body := gin.H{
+			"type":     "about:blank",
+			"title":    http.StatusText(status),
+			"status":   status,
+			"detail":   message,
+			"instance": c.Request.URL.Path,
+		}
+		if requestID != "" {
+			body["request_id"] = requestID
+		}
+		// 已设置的 Content-Type 不会被 JSON 渲染覆盖
+		c.Header("Content-Type", problemContentType)
+		c.AbortWithStatusJSON(status, body)
+		return
+	}
+
+	body := gin.H{
+		"code":    status,
+		"message": message,
+	}
+	if requestID != "" {
+		body["request_id"] = requestID
+	}
+	c.AbortWithStatusJSON(status, body)
+}
+
+// requestAttrs 日志中的请求信息
+func requestAttrs(c *gin.Context) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("route", accessRoute(c, nil)),
+		slog.String("path", c.Request.URL.Path),
+		slog.String("client_ip", GetClientIP(c)),
+	}
+	if id := accessRequestID(c); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	return attrs
+}
+
+// ErrorHandler 将处理函数通过 c.Error 记录的最后一个错误转换为 JSON 错误响应（响应已写入时不处理），5xx 错误会记录日志
+func ErrorHandler(opts ...ErrorOption) gin.HandlerFunc {
+	cfg := newErrorConfig(opts)
+	return func(c *gin.Context) {
+		c.Next()
+		last := c.Errors.Last()
+		if last == nil {
+			return
+		}
+		status := cfg.status(last.Err)
+		if status >= http.StatusInternalServerError {
+			attrs := append(requestAttrs(c), slog.Int("status", status), slog.Any("error", last.Err))
+			cfg.logger.LogAttrs(c.Request.Context(), slog.LevelError, "request failed", attrs...)
+		}
+		cfg.render(c, status, last.Err)
+	}
+}
+
+// Handle 将返回 error 的处理函数适配为 gin.HandlerFunc，返回的错误通过 c.Error 记录并中止，由 ErrorHandler 生成响应
+func Handle(fn func(c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := fn(c); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
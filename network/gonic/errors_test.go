@@ -0,0 +1,79 @@
+package gonic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	loop "github.com/hargeek/gopkg/loop"
+	"github.com/hargeek/gopkg/network"
+	"github.com/stretchr/testify/assert"
+)
+
+var errNotFound = errors.New("record not found")
+
+func TestErrorHandler(t *testing.T) {
+	var buf bytes.Buffer
+	r := gin.New()
+	r.Use(ErrorHandler(WithErrorStatus(errNotFound, http.StatusNotFound), WithErrorLogger(slog.New(slog.NewJSONHandler(&buf, nil)))))
+	r.GET("/typed", Handle(func(c *gin.Context) error {
+		return WrapError(http.StatusBadRequest, io.ErrUnexpectedEOF, "invalid body")
+	}))
+	r.GET("/mapped", Handle(func(c *gin.Context) error {
+		return fmt.Errorf("load user: %w", errNotFound)
+	}))
+	r.GET("/internal", Handle(func(c *gin.Context) error {
+		return errors.New("db password wrong")
+	}))
+	r.GET("/zero", Handle(func(c *gin.Context) error {
+		return &Error{Message: "no status"}
+	}))
+	r.GET("/ok", Handle(func(c *gin.Context) error {
+		c.String(http.StatusOK, "ok")
+		return nil
+	}))
+	r.GET("/written", Handle(func(c *gin.Context) error {
+		c.String(http.StatusAccepted, "partial")
+		return errors.New("late")
+	}))
+
+	w := serve(r, http.MethodGet, "/typed", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"code":400,"message":"invalid body"}`, w.Body.String())
+
+	w = serve(r, http.MethodGet, "/mapped", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":404,"message":"Not Found"}`, w.Body.String())
+	assert.Empty(t, buf.String())
+
+	w = serve(r, http.MethodGet, "/internal", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+	assert.Contains(t, buf.String(), "db password wrong")
+
+	// 未设置状态码的 *Error 按 500 响应
+	w = serve(r, http.MethodGet, "/zero", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"code":500,"message":"no status"}`, w.Body.String())
+
+	assert.Equal(t, "ok", serve(r, http.MethodGet, "/ok", nil).Body.String())
+	w = serve(r, http.MethodGet, "/written", nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "partial", w.Body.String())
+}
+
+func TestErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, ErrorStatus(fmt.Errorf("x: %w", NewError(http.StatusConflict, "conflict"))))
+	assert.Equal(t, http.StatusTooManyRequests, ErrorStatus(loop.StatusError(http.StatusTooManyRequests, nil)))
+	assert.Equal(t, http.StatusBadGateway, ErrorStatus(&network.HTTPError{Code: http.StatusUnauthorized}))
+	assert.Equal(t, http.StatusGatewayTimeout, ErrorStatus(context.DeadlineExceeded))
+	assert.Equal(t, http.StatusInternalServerError, ErrorStatus(errors.New("x")))
+	assert.Equal(t, http.StatusInternalServerError, ErrorStatus(NewError(0, "x")))
+	assert.Equal(t, http.StatusInternalServerError, ErrorStatus(NewError(http.StatusOK, "x")))
+}
@@ -1,15 +1,47 @@
 package gonic
 
 import (
+	"net/http"
+	"net/netip"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
 )
 
+// ClientIPKey ClientIPMiddleware 在 gin.Context 中保存解析结果使用的 key
+const ClientIPKey = "gonic.client_ip"
+
 // GetClientIP 获取客户端真实IP
+// 若已使用 ClientIPMiddleware，返回可信代理链解析出的地址；否则返回直连对端地址，不读取任何请求头。
+// 位于反向代理之后时应配置 ClientIPMiddleware，RateLimit、IPAccessControl、AccessLog 等均使用该函数
 func GetClientIP(c *gin.Context) string {
-	// 1. X-Forwarded-For
+	if ip, ok := ResolvedClientIP(c); ok {
+		return ip.Addr.String()
+	}
+	return RemoteIP(c)
+}
+
+// RemoteIP 返回直连对端（c.Request.RemoteAddr）的地址，无法解析时返回原值
+func RemoteIP(c *gin.Context) string {
+	if addr, err := network.ParseIP(c.Request.RemoteAddr); err == nil {
+		return addr.String()
+	}
+	return c.Request.RemoteAddr
+}
+
+// GuessClientIP 依次按 X-Forwarded-For（最左侧）、X-Real-Ip、Remoteip 请求头猜测客户端IP，都没有时返回直连对端地址。
+// 请求头完全由客户端控制、可被伪造，只适合展示，不要用于限流、审计或鉴权，这些场景请使用 ClientIPMiddleware
+func GuessClientIP(c *gin.Context) string {
+	// 1. X-Forwarded-For（可能为逗号分隔的列表，取最左侧的客户端地址）
 	ip := c.GetHeader("X-Forwarded-For")
 	if ip != "" {
-		return ip
+		if i := strings.IndexByte(ip, ','); i >= 0 {
+			ip = ip[:i]
+		}
+		if ip = strings.TrimSpace(ip); ip != "" {
+			return ip
+		}
 	}
 
 	// 2. X-Real-Ip
@@ -24,6 +56,154 @@ func GetClientIP(c *gin.Context) string {
 		return ip
 	}
 
-	// 4. Gin默认
-	return c.ClientIP()
+	// 4. 直连对端
+	return RemoteIP(c)
+}
+
+// ClientIP 可信代理链解析得到的客户端地址
+type ClientIP struct {
+	Addr  netip.Addr   // 客户端地址
+	Chain []netip.Addr // 被信任的代理地址，从直连对端开始由近及远
+}
+
+// IPResolver 基于可信代理网段解析客户端地址：从直连对端开始自右向左遍历转发头，
+// 跳过可信代理，第一个不可信的地址即为客户端地址，可并发使用
+type IPResolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// IPResolverOption IPResolver 的配置项
+type IPResolverOption func(*IPResolver)
+
+// WithClientIPHeader 设置读取代理链的请求头，默认 X-Forwarded-For；
+// 设为 "Forwarded" 时按 RFC 7239 解析 for= 参数，其他请求头按逗号分隔的地址列表解析（如 X-Real-Ip）
+func WithClientIPHeader(name string) IPResolverOption {
+	return func(r *IPResolver) {
+		r.header = http.CanonicalHeaderKey(name)
+	}
+}
+
+// NewIPResolver 创建解析器，trustedProxies 为可信代理的 CIDR 或单个 IP
+func NewIPResolver(trustedProxies []string, opts ...IPResolverOption) (*IPResolver, error) {
+	r := &IPResolver{header: "X-Forwarded-For"}
+	for _, s := range trustedProxies {
+		p, err := network.ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		r.trusted = append(r.trusted, p)
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// IsTrusted 判断地址是否属于可信代理
+func (r *IPResolver) IsTrusted(addr netip.Addr) bool {
+	for _, p := range r.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve 解析请求的客户端地址，直连对端地址无法解析时返回错误
+// 转发头中出现无法解析的地址（如 Forwarded 的 "unknown"）时停止遍历，返回最后一个可信代理的地址
+func (r *IPResolver) Resolve(req *http.Request) (ClientIP, error) {
+	remote, err := network.ParseIP(req.RemoteAddr)
+	if err != nil {
+		return ClientIP{}, err
+	}
+	if !r.IsTrusted(remote) {
+		return ClientIP{Addr: remote}, nil
+	}
+
+	chain := []netip.Addr{remote}
+	hops := r.hops(req.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := network.ParseIP(hops[i])
+		if err != nil {
+			break
+		}
+		if !r.IsTrusted(addr) {
+			return ClientIP{Addr: addr, Chain: chain}, nil
+		}
+		chain = append(chain, addr)
+	}
+	// 全部可信或遇到无法解析的地址：取最远的可信地址
+	return ClientIP{Addr: chain[len(chain)-1], Chain: chain[:len(chain)-1]}, nil
+}
+
+// hops 从请求头中取出代理链上的地址，按从远到近的顺序
+func (r *IPResolver) hops(header http.Header) []string {
+	var hops []string
+	for _, line := range header.Values(r.header) {
+		if r.header == "Forwarded" {
+			hops = append(hops, parseForwarded(line)...)
+			continue
+		}
+		for _, part := range strings.Split(line, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				hops = append(hops, part)
+			}
+		}
+	}
+	return hops
+}
+
+// parseForwarded 解析 RFC 7239 Forwarded 头，返回每个元素的 for= 值，缺少 for= 的元素返回空字符串
+func parseForwarded(value string) []string {
+	var hops []string
+	for _, element := range splitQuoted(value, ',') {
+		var forValue string
+		for _, pair := range splitQuoted(element, ';') {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(k), "for") {
+				forValue = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+		hops = append(hops, forValue)
+	}
+	return hops
+}
+
+// splitQuoted 按分隔符拆分字符串，忽略双引号内的分隔符
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	inQuote, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && inQuote:
+			i++
+		case s[i] == '"':
+			inQuote = !inQuote
+		case s[i] == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// ClientIPMiddleware 使用 resolver 解析客户端地址并保存到 gin.Context，之后 GetClientIP 会返回该结果
+func ClientIPMiddleware(resolver *IPResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ip, err := resolver.Resolve(c.Request); err == nil {
+			c.Set(ClientIPKey, ip)
+		}
+		c.Next()
+	}
+}
+
+// ResolvedClientIP 获取 ClientIPMiddleware 保存的解析结果
+func ResolvedClientIP(c *gin.Context) (ClientIP, bool) {
+	v, ok := c.Get(ClientIPKey)
+	if !ok {
+		return ClientIP{}, false
+	}
+	ip, ok := v.(ClientIP)
+	return ip, ok
 }
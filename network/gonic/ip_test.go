@@ -0,0 +1,91 @@
+package gonic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPResolver(t *testing.T) {
+	resolver, err := NewIPResolver([]string{"10.0.0.0/8", "192.168.1.1", "2001:db8::/32"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+		chain  []string
+	}{
+		{name: "直连不可信，忽略XFF", remote: "203.0.113.9:1234", xff: "1.1.1.1", want: "203.0.113.9"},
+		{name: "单层代理", remote: "10.0.0.1:80", xff: "198.51.100.7", want: "198.51.100.7", chain: []string{"10.0.0.1"}},
+		{name: "伪造的XFF被忽略", remote: "10.0.0.1:80", xff: "6.6.6.6, 198.51.100.7, 192.168.1.1", want: "198.51.100.7", chain: []string{"10.0.0.1", "192.168.1.1"}},
+		{name: "IPv4映射地址", remote: "[::ffff:10.0.0.1]:80", xff: "::ffff:198.51.100.7", want: "198.51.100.7", chain: []string{"10.0.0.1"}},
+		{name: "IPv6与zone", remote: "[2001:db8::1]:443", xff: "fe80::1%eth0", want: "fe80::1", chain: []string{"2001:db8::1"}},
+		{name: "全部可信取最左", remote: "10.0.0.1:80", xff: "10.0.0.3, 10.0.0.2", want: "10.0.0.3", chain: []string{"10.0.0.1", "10.0.0.2"}},
+		{name: "无效地址停止遍历", remote: "10.0.0.1:80", xff: "1.1.1.1, garbage", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("X-Forwarded-For", tt.xff)
+			ip, err := resolver.Resolve(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, ip.Addr.String())
+			var chain []string
+			for _, a := range ip.Chain {
+				chain = append(chain, a.String())
+			}
+			assert.Equal(t, tt.chain, chain)
+		})
+	}
+}
+
+func TestIPResolverForwarded(t *testing.T) {
+	resolver, err := NewIPResolver([]string{"10.0.0.0/8"}, WithClientIPHeader("forwarded"))
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:80"
+	req.Header.Add("Forwarded", `for=192.0.2.43, for="[2001:db8:cafe::17]:4711";proto=https`)
+	req.Header.Add("Forwarded", `for=10.0.0.2;by=10.0.0.1`)
+	ip, err := resolver.Resolve(req)
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("2001:db8:cafe::17"), ip.Addr)
+	assert.Len(t, ip.Chain, 2)
+}
+
+func TestGetClientIP(t *testing.T) {
+	handler := func(c *gin.Context) { c.String(http.StatusOK, GetClientIP(c)) }
+
+	t.Run("未配置解析器时忽略请求头", func(t *testing.T) {
+		r := gin.New()
+		r.GET("/", handler)
+		w := serveFrom(r, http.MethodGet, "/", "[::ffff:203.0.113.5]:4000", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Real-Ip": "10.0.0.1"})
+		assert.Equal(t, "203.0.113.5", w.Body.String())
+	})
+
+	t.Run("使用可信代理解析结果", func(t *testing.T) {
+		resolver, _ := NewIPResolver([]string{"192.0.2.0/24"})
+		r := gin.New()
+		r.Use(ClientIPMiddleware(resolver))
+		r.GET("/", handler)
+		// httptest 默认 RemoteAddr 为 192.0.2.1:1234
+		w := serve(r, http.MethodGet, "/", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.7"})
+		assert.Equal(t, "198.51.100.7", w.Body.String())
+	})
+}
+
+func TestGuessClientIP(t *testing.T) {
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, GuessClientIP(c)) })
+
+	assert.Equal(t, "1.1.1.1", serve(r, http.MethodGet, "/", map[string]string{"X-Forwarded-For": "1.1.1.1, 10.0.0.1"}).Body.String())
+	assert.Equal(t, "10.0.0.2", serve(r, http.MethodGet, "/", map[string]string{"X-Real-Ip": "10.0.0.2"}).Body.String())
+	assert.Equal(t, "192.0.2.1", serve(r, http.MethodGet, "/", nil).Body.String())
+}
@@ -0,0 +1,137 @@
+package gonic
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+	"github.com/hargeek/gopkg/ratelimit"
+)
+
+// RateLimitOption RateLimit 中间件的配置项
+type RateLimitOption func(*rateLimitConfig)
+
+type rateLimitConfig struct {
+	keyFunc         func(c *gin.Context) string
+	routes          map[string]routeLimit
+	exceededHandler func(c *gin.Context, res ratelimit.Result)
+	limiterOpts     []ratelimit.Option
+}
+
+type routeLimit struct {
+	limit  int
+	window time.Duration
+}
+
+// WithRateLimitKeyFunc 设置限流 key 的获取方式，默认使用 GetClientIP（不信任客户端请求头，位于代理之后时需配置 ClientIPMiddleware），可改为 API Key、用户ID 等
+func WithRateLimitKeyFunc(fn func(c *gin.Context) string) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		cfg.keyFunc = fn
+	}
+}
+
+// WithRouteRateLimit 为指定路由单独设置限流，path 可以是 gin 的路由模式（如 "/api/v1/user/:id"，与 c.FullPath() 匹配），
+// 也可以是请求路径，此时与请求路径都经过 network.NormalizePath 标准化后匹配（如 "/api/v1/user/*" 可匹配 "/api/v1/user/123"）；
+// 命中的路由使用该限额代替默认限额
+func WithRouteRateLimit(path string, limit int, window time.Duration) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		cfg.routes[path] = routeLimit{limit: limit, window: window}
+	}
+}
+
+// WithRateLimitExceededHandler 设置超出限额时的响应，默认返回 429 JSON；响应头已在调用前设置
+func WithRateLimitExceededHandler(fn func(c *gin.Context, res ratelimit.Result)) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		cfg.exceededHandler = fn
+	}
+}
+
+// WithRateLimiterOptions 传递给底层 ratelimit.SlidingWindow 的配置项，如 ratelimit.WithClock
+func WithRateLimiterOptions(opts ...ratelimit.Option) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		cfg.limiterOpts = append(cfg.limiterOpts, opts...)
+	}
+}
+
+// RateLimit 按客户端 key 限流的中间件：默认每个 key 在 window 内最多 limit 次请求，limit<=0 表示只限制 WithRouteRateLimit 指定的路由
+// 每个响应都会带上 X-RateLimit-Limit、X-RateLimit-Remaining、X-RateLimit-Reset（秒），超出时返回 429 和 Retry-After（秒）
+func RateLimit(limit int, window time.Duration, opts ...RateLimitOption) gin.HandlerFunc {
+	cfg := &rateLimitConfig{
+		keyFunc:         GetClientIP,
+		routes:          make(map[string]routeLimit),
+		exceededHandler: defaultRateLimitExceeded,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var defaultLimiter *ratelimit.SlidingWindow
+	if limit > 0 {
+		defaultLimiter = ratelimit.NewSlidingWindow(limit, window, cfg.limiterOpts...)
+	}
+	// 同一配置的两种匹配方式共用一个限流器
+	fullPathLimiters := make(map[string]*ratelimit.SlidingWindow, len(cfg.routes))
+	routeLimiters := make(map[string]*ratelimit.SlidingWindow, len(cfg.routes))
+	for path, rl := range cfg.routes {
+		l := ratelimit.NewSlidingWindow(rl.limit, rl.window, cfg.limiterOpts...)
+		fullPathLimiters[path] = l
+		routeLimiters[network.NormalizePath(path)] = l
+	}
+
+	return func(c *gin.Context) {
+		limiter := defaultLimiter
+		if len(routeLimiters) > 0 {
+			if l, ok := routeLimiter(c, fullPathLimiters, routeLimiters); ok {
+				limiter = l
+			}
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		res := limiter.Take(cfg.keyFunc(c))
+		h := c.Writer.Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(res.ResetAfter), 10))
+		if !res.Allowed {
+			h.Set("Retry-After", strconv.FormatInt(ceilSeconds(res.RetryAfter), 10))
+			cfg.exceededHandler(c, res)
+			if !c.IsAborted() {
+				c.Abort()
+			}
+			return
+		}
+		c.Next()
+	}
+}
+
+// routeLimiter 优先按 gin 路由模式匹配，未匹配时按标准化后的请求路径匹配
+func routeLimiter(c *gin.Context, byFullPath, byPath map[string]*ratelimit.SlidingWindow) (*ratelimit.SlidingWindow, bool) {
+	if fullPath := c.FullPath(); fullPath != "" {
+		if l, ok := byFullPath[fullPath]; ok {
+			return l, true
+		}
+	}
+	l, ok := byPath[network.NormalizePath(c.Request.URL.Path)]
+	return l, ok
+}
+
+func defaultRateLimitExceeded(c *gin.Context, _ ratelimit.Result) {
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"code":    http.StatusTooManyRequests,
+		"message": "too many requests",
+	})
+}
+
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(d.Seconds()))
+}
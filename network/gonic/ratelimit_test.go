@@ -0,0 +1,99 @@
+package gonic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve 发起一次测试请求
+func serve(r http.Handler, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	return serveFrom(r, method, target, "", header)
+}
+
+// serveFrom 以 remoteAddr 作为直连对端发送请求，为空时使用 httptest 默认的 192.0.2.1:1234
+func serveFrom(r http.Handler, method, target, remoteAddr string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if remoteAddr != "" {
+		req.RemoteAddr = remoteAddr
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+
+	t.Run("TC01: 按客户端IP限流", func(t *testing.T) {
+		r := gin.New()
+		r.Use(RateLimit(2, time.Minute))
+		r.GET("/ping", ok)
+
+		// 伪造的请求头不影响限流 key
+		spoofed := map[string]string{"X-Real-Ip": "10.0.0.9"}
+		w := serveFrom(r, http.MethodGet, "/ping", "10.0.0.1:1000", spoofed)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("X-RateLimit-Reset"))
+
+		serveFrom(r, http.MethodGet, "/ping", "10.0.0.1:1001", map[string]string{"X-Real-Ip": "10.0.0.8"})
+		w = serveFrom(r, http.MethodGet, "/ping", "10.0.0.1:1002", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+		w = serveFrom(r, http.MethodGet, "/ping", "10.0.0.2:1000", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("TC02: 自定义key与路由限额", func(t *testing.T) {
+		r := gin.New()
+		r.Use(RateLimit(0, 0,
+			WithRateLimitKeyFunc(func(c *gin.Context) string { return c.GetHeader("X-Api-Key") }),
+			WithRouteRateLimit("/api/v1/user/1", 1, time.Minute),
+		))
+		r.GET("/api/v1/user/:id", ok)
+		r.GET("/api/v1/users", ok)
+
+		key := map[string]string{"X-Api-Key": "k1"}
+		assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/api/v1/user/100", key).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(r, http.MethodGet, "/api/v1/user/200", key).Code)
+		assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/api/v1/user/200", map[string]string{"X-Api-Key": "k2"}).Code)
+
+		// 未配置限额的路由不受限制
+		for i := 0; i < 3; i++ {
+			w := serve(r, http.MethodGet, "/api/v1/users", key)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+
+	t.Run("TC03: 按 gin 路由模式匹配", func(t *testing.T) {
+		r := gin.New()
+		r.Use(RateLimit(0, 0,
+			WithRateLimitKeyFunc(func(c *gin.Context) string { return "k" }),
+			WithRouteRateLimit("/api/user/:id", 1, time.Minute),
+		))
+		r.GET("/api/user/:id", ok)
+		r.GET("/api/user/:id/orders", ok)
+
+		assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/api/user/abc", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(r, http.MethodGet, "/api/user/def", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(r, http.MethodGet, "/api/user/1", nil).Code)
+		assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/api/user/abc/orders", nil).Code)
+		assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/api/user/abc/orders", nil).Code)
+	})
+}
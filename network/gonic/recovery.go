@@ -0,0 +1,75 @@
+package gonic
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery 捕获处理过程中的 panic：记录带堆栈和请求信息（方法、路由、客户端IP、请求ID）的错误日志，
+// 并返回 500 JSON 错误响应（WithProblemJSON 时为 problem+json）；客户端断开连接导致的 panic 只记录警告，不写响应。
+// panic 的值为 *Error 时按其状态码和消息响应
+func Recovery(opts ...ErrorOption) gin.HandlerFunc {
+	cfg := newErrorConfig(opts)
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// http.ErrAbortHandler 用于主动中止响应，交给 net/http 处理
+				panic(rec)
+			}
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			attrs := append(requestAttrs(c), slog.Any("panic", rec))
+			if isBrokenPipe(err) {
+				cfg.logger.LogAttrs(c.Request.Context(), slog.LevelWarn, "client connection closed", attrs...)
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+
+			attrs = append(attrs, slog.String("stack", string(debug.Stack())))
+			cfg.logger.LogAttrs(c.Request.Context(), slog.LevelError, "panic recovered", attrs...)
+
+			status := http.StatusInternalServerError
+			var e *Error
+			if errors.As(err, &e) {
+				status = errorStatusCode(e.Status)
+			} else {
+				err = nil // 不向客户端暴露 panic 内容
+			}
+			cfg.render(c, status, err)
+		}()
+		c.Next()
+	}
+}
+
+// isBrokenPipe 判断是否为客户端断开连接导致的写入错误
+func isBrokenPipe(err error) bool {
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		var sysErr *os.SyscallError
+		if errors.As(opErr.Err, &sysErr) {
+			msg := strings.ToLower(sysErr.Error())
+			return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package gonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := gin.New()
+	r.Use(RequestID(), Recovery(WithErrorLogger(logger)))
+	r.GET("/boom/:id", func(c *gin.Context) { panic("secret detail") })
+	r.GET("/typed", func(c *gin.Context) { panic(NewError(http.StatusConflict, "version conflict")) })
+	r.GET("/zero", func(c *gin.Context) { panic(&Error{Message: "no status"}) })
+	r.GET("/pipe", func(c *gin.Context) { panic(syscall.EPIPE) })
+
+	t.Run("TC01: 捕获 panic 并记录日志", func(t *testing.T) {
+		w := serveFrom(r, http.MethodGet, "/boom/1", "10.0.0.1:1000", map[string]string{"X-Real-Ip": "6.6.6.6", network.RequestIDHeader: "req-1"})
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"code":500,"message":"Internal Server Error","request_id":"req-1"}`, w.Body.String())
+		assert.NotContains(t, w.Body.String(), "secret")
+
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "panic recovered", record["msg"])
+		assert.Equal(t, "secret detail", record["panic"])
+		assert.Equal(t, "/boom/:id", record["route"])
+		assert.Equal(t, "10.0.0.1", record["client_ip"])
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.Contains(t, record["stack"], "recovery_test.go")
+	})
+
+	t.Run("TC02: 类型化 panic 与断开连接", func(t *testing.T) {
+		w := serve(r, http.MethodGet, "/typed", nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"message":"version conflict"`)
+
+		w = serve(r, http.MethodGet, "/zero", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":500,"message":"no status"`)
+
+		buf.Reset()
+		w = serve(r, http.MethodGet, "/pipe", nil)
+		assert.Empty(t, w.Body.String())
+		assert.Contains(t, buf.String(), `"level":"WARN"`)
+		assert.NotContains(t, buf.String(), "stack")
+	})
+
+	t.Run("TC03: problem+json", func(t *testing.T) {
+		r := gin.New()
+		r.Use(Recovery(WithProblemJSON(), WithErrorLogger(logger)))
+		r.GET("/boom", func(c *gin.Context) { panic("x") })
+		w := serve(r, http.MethodGet, "/boom", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal Server Error","instance":"/boom"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,122 @@
+package gonic
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+)
+
+// gin 上下文中保存请求ID 和链路信息的 key
+const (
+	RequestIDKey    = "gonic.request_id"
+	TraceContextKey = "gonic.trace_context"
+)
+
+// maxRequestIDLength 接受的请求ID 最大长度
+const maxRequestIDLength = 128
+
+// RequestIDOption RequestID 中间件的配置项
+type RequestIDOption func(*requestIDConfig)
+
+type requestIDConfig struct {
+	header    string
+	generator func() string
+	trust     bool
+}
+
+// WithRequestIDHeader 设置请求ID 使用的请求头，默认 network.RequestIDHeader
+func WithRequestIDHeader(header string) RequestIDOption {
+	return func(cfg *requestIDConfig) {
+		cfg.header = header
+	}
+}
+
+// WithRequestIDGenerator 设置请求ID 生成函数，默认 network.NewRequestID
+func WithRequestIDGenerator(fn func() string) RequestIDOption {
+	return func(cfg *requestIDConfig) {
+		cfg.generator = fn
+	}
+}
+
+// WithTrustIncomingRequestID 是否沿用请求中携带的请求ID，默认 true；面向公网时可关闭，总是生成新的
+func WithTrustIncomingRequestID(trust bool) RequestIDOption {
+	return func(cfg *requestIDConfig) {
+		cfg.trust = trust
+	}
+}
+
+// RequestID 请求ID 与 W3C Trace Context 中间件：
+// 沿用请求中的请求ID（长度不超过 128 的可见 ASCII 字符）或生成新的；解析 traceparent/tracestate，
+// 有效时在同一链路中为本服务生成新的 span，否则开始新的链路。
+// 结果保存到 request context（network.RequestIDFromContext、network.TraceContextFromContext）和 gin 上下文，
+// 并写入响应头；出站请求可用 network.InjectTraceHeaders 或 network.TraceContextTransport 继续传递
+func RequestID(opts ...RequestIDOption) gin.HandlerFunc {
+	cfg := &requestIDConfig{
+		header:    network.RequestIDHeader,
+		generator: network.NewRequestID,
+		trust:     true,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *gin.Context) {
+		id := ""
+		if cfg.trust {
+			id = c.GetHeader(cfg.header)
+			if !validRequestID(id) {
+				id = ""
+			}
+		}
+		if id == "" {
+			id = cfg.generator()
+		}
+
+		tc, err := network.TraceContextFromHeader(c.Request.Header)
+		if err == nil {
+			tc = tc.ChildSpan()
+		} else {
+			tc = network.NewTraceContext()
+		}
+
+		ctx := network.ContextWithRequestID(c.Request.Context(), id)
+		ctx = network.ContextWithTraceContext(ctx, tc)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(RequestIDKey, id)
+		c.Set(TraceContextKey, tc)
+
+		c.Header(cfg.header, id)
+		c.Header(network.TraceParentHeader, tc.String())
+		if tc.State != "" {
+			c.Header(network.TraceStateHeader, tc.State)
+		}
+		c.Next()
+	}
+}
+
+// GetRequestID 返回 RequestID 中间件设置的请求ID，未设置时返回空字符串
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+// GetTraceContext 返回 RequestID 中间件设置的链路信息
+func GetTraceContext(c *gin.Context) (network.TraceContext, bool) {
+	v, ok := c.Get(TraceContextKey)
+	if !ok {
+		return network.TraceContext{}, false
+	}
+	tc, ok := v.(network.TraceContext)
+	return tc, ok
+}
+
+// validRequestID 检查请求ID 是否为长度合适的可见 ASCII 字符串，防止日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
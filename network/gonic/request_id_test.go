@@ -0,0 +1,74 @@
+package gonic
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var (
+		gotID    string
+		gotCtxID string
+		gotTrace network.TraceContext
+	)
+	handler := func(c *gin.Context) {
+		gotID = GetRequestID(c)
+		gotCtxID = network.RequestIDFromContext(c.Request.Context())
+		gotTrace, _ = network.TraceContextFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	}
+
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", handler)
+
+	t.Run("TC01: 沿用请求ID 与链路", func(t *testing.T) {
+		parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		w := serve(r, http.MethodGet, "/", map[string]string{
+			network.RequestIDHeader:   "abc-123",
+			network.TraceParentHeader: parent,
+			network.TraceStateHeader:  "vendor=x",
+		})
+		assert.Equal(t, "abc-123", gotID)
+		assert.Equal(t, "abc-123", gotCtxID)
+		assert.Equal(t, "abc-123", w.Header().Get(network.RequestIDHeader))
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", gotTrace.TraceIDString())
+		assert.NotEqual(t, "00f067aa0ba902b7", gotTrace.SpanIDString())
+		assert.Equal(t, "vendor=x", gotTrace.State)
+		assert.Equal(t, gotTrace.String(), w.Header().Get(network.TraceParentHeader))
+		assert.Equal(t, "vendor=x", w.Header().Get(network.TraceStateHeader))
+	})
+
+	t.Run("TC02: 生成新的请求ID 与链路", func(t *testing.T) {
+		w := serve(r, http.MethodGet, "/", map[string]string{
+			network.RequestIDHeader:   strings.Repeat("x", 200),
+			network.TraceParentHeader: "garbage",
+		})
+		assert.Len(t, gotID, 32)
+		assert.Equal(t, gotID, w.Header().Get(network.RequestIDHeader))
+		assert.True(t, gotTrace.IsValid())
+		assert.Empty(t, w.Header().Get(network.TraceStateHeader))
+
+		serve(r, http.MethodGet, "/", map[string]string{network.RequestIDHeader: "bad id\n"})
+		assert.Len(t, gotID, 32)
+	})
+
+	t.Run("TC03: 自定义配置", func(t *testing.T) {
+		r := gin.New()
+		r.Use(RequestID(
+			WithRequestIDHeader("X-Trace-Id"),
+			WithRequestIDGenerator(func() string { return "fixed" }),
+			WithTrustIncomingRequestID(false),
+		))
+		r.GET("/", handler)
+		w := serve(r, http.MethodGet, "/", map[string]string{"X-Trace-Id": "client"})
+		assert.Equal(t, "fixed", gotID)
+		assert.Equal(t, "fixed", w.Header().Get("X-Trace-Id"))
+	})
+}
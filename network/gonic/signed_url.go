@@ -0,0 +1,34 @@
+package gonic
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+)
+
+// SignedURL 校验签名链接的中间件（签名由 network.URLSigner 生成）：过期返回 410，其他校验失败返回 403；
+// onError 不为 nil 时由其处理失败的请求（需要自行中止），否则返回默认的 JSON 错误
+func SignedURL(signer *network.URLSigner, onError func(c *gin.Context, err error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := signer.VerifyRequest(c.Request)
+		if err == nil {
+			c.Next()
+			return
+		}
+		if onError != nil {
+			onError(c, err)
+			return
+		}
+
+		code, message := http.StatusForbidden, "invalid signature"
+		if errors.Is(err, network.ErrSignatureExpired) {
+			code, message = http.StatusGone, "link expired"
+		}
+		c.AbortWithStatusJSON(code, gin.H{
+			"code":    code,
+			"message": message,
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package gonic
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hargeek/gopkg/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedURL(t *testing.T) {
+	signer, err := network.NewURLSigner("k1", map[string][]byte{"k1": []byte("secret")})
+	assert.NoError(t, err)
+
+	r := gin.New()
+	r.GET("/download/:name", SignedURL(signer, nil), func(c *gin.Context) { c.String(http.StatusOK, c.Param("name")) })
+
+	link, err := signer.Sign(http.MethodGet, "/download/report.pdf", time.Minute)
+	assert.NoError(t, err)
+	w := serve(r, http.MethodGet, link, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "report.pdf", w.Body.String())
+
+	w = serve(r, http.MethodGet, strings.Replace(link, "report", "secret", 1), nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"code":403,"message":"invalid signature"}`, w.Body.String())
+
+	expired, err := signer.Sign(http.MethodGet, "/download/report.pdf", -time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusGone, serve(r, http.MethodGet, expired, nil).Code)
+
+	var got error
+	r = gin.New()
+	r.GET("/download/:name", SignedURL(signer, func(c *gin.Context, err error) {
+		got = err
+		c.AbortWithStatus(http.StatusNotFound)
+	}))
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, "/download/x", nil).Code)
+	assert.ErrorIs(t, got, network.ErrInvalidSignature)
+}
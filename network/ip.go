@@ -0,0 +1,50 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ParsePrefix 解析 CIDR 或单个 IP（视为 /32 或 /128），IPv4 映射的 IPv6 地址会转换为 IPv4
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("无效的CIDR %q: %w", s, err)
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := ParseIP(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ParseIP 解析并标准化IP地址：支持 "ip"、"ip:port"、"[ipv6]:port" 及带引号的形式，
+// 去掉 IPv6 zone，IPv4 映射的 IPv6 地址转换为 IPv4
+func ParseIP(s string) (netip.Addr, error) {
+	host := strings.Trim(strings.TrimSpace(s), `"`)
+	if strings.HasPrefix(host, "[") {
+		end := strings.IndexByte(host, ']')
+		if end < 0 {
+			return netip.Addr{}, fmt.Errorf("无效的IP地址: %q", s)
+		}
+		host = host[1:end]
+	} else if strings.Count(host, ":") == 1 {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("无效的IP地址: %q", s)
+	}
+	return addr.Unmap().WithZone(""), nil
+}
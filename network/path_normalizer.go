@@ -0,0 +1,340 @@
+package network
+
+import (
+	"strings"
+	"sync"
+)
+
+// DefaultOverflowPath 超过基数上限后新路径统一返回的值
+const DefaultOverflowPath = "/:other"
+
+// PathPattern 路径段匹配规则，匹配的段会被替换为 Placeholder
+type PathPattern struct {
+	Name        string
+	Placeholder string
+	Match       func(segment string) bool
+}
+
+// 内置的路径段匹配规则
+var (
+	// IntPattern 十进制整数（可带负号）
+	IntPattern = PathPattern{Name: "int", Placeholder: ":id", Match: isIntSegment}
+	// UUIDPattern 8-4-4-4-12 格式的 UUID
+	UUIDPattern = PathPattern{Name: "uuid", Placeholder: ":uuid", Match: isUUIDSegment}
+	// ULIDPattern 26 位 Crockford Base32 编码的 ULID
+	ULIDPattern = PathPattern{Name: "ulid", Placeholder: ":ulid", Match: isULIDSegment}
+	// ObjectIDPattern 24 位十六进制的 MongoDB ObjectID
+	ObjectIDPattern = PathPattern{Name: "objectid", Placeholder: ":oid", Match: isObjectIDSegment}
+	// HexPattern 长度不小于 16 的十六进制串（如哈希值）
+	HexPattern = PathPattern{Name: "hex", Placeholder: ":hex", Match: isHexSegment}
+	// EmailPattern 邮箱地址（包括 URL 编码后的 %40）
+	EmailPattern = PathPattern{Name: "email", Placeholder: ":email", Match: isEmailSegment}
+	// Base64Pattern 长度不小于 32、同时包含大写字母、小写字母和数字，并且含有 "-"、"_"、"+"、"=" 之一或形如 JWT（eyJ 开头、含两个 "."）的
+	// base64/base64url 串（如令牌）；较长的驼峰或短横线命名的路由段仍可能被误判，因此不在默认规则中，需要时通过 WithExtraPathPatterns 添加
+	Base64Pattern = PathPattern{Name: "base64", Placeholder: ":token", Match: isBase64Segment}
+)
+
+// DefaultPathPatterns 返回默认的匹配规则，按顺序匹配，先匹配到的生效
+func DefaultPathPatterns() []PathPattern {
+	return []PathPattern{IntPattern, UUIDPattern, ObjectIDPattern, ULIDPattern, HexPattern, EmailPattern}
+}
+
+// PathNormalizer 可配置的路径标准化器，用于生成监控指标标签等需要控制基数的场景：
+// 优先匹配已注册的路由模板，否则将匹配规则的路径段替换为占位符，如 /api/v1/user/123/orders/456 -> /api/v1/user/:id/orders/:id；
+// 设置基数上限后，超出上限的新路径统一返回溢出值。可并发使用
+type PathNormalizer struct {
+	patterns     []PathPattern
+	maxPaths     int
+	overflowPath string
+
+	mu        sync.RWMutex
+	templates []pathTemplate
+	seen      map[string]struct{}
+}
+
+// PathNormalizerOption PathNormalizer 的配置项
+type PathNormalizerOption func(*PathNormalizer)
+
+// WithPathPatterns 替换默认的匹配规则
+func WithPathPatterns(patterns ...PathPattern) PathNormalizerOption {
+	return func(n *PathNormalizer) {
+		n.patterns = append([]PathPattern(nil), patterns...)
+	}
+}
+
+// WithExtraPathPatterns 在默认规则之前追加匹配规则
+func WithExtraPathPatterns(patterns ...PathPattern) PathNormalizerOption {
+	return func(n *PathNormalizer) {
+		n.patterns = append(append([]PathPattern(nil), patterns...), n.patterns...)
+	}
+}
+
+// WithPathTemplates 注册路由模板，参数段写作 :name、{name} 或 *，如 /api/v1/users/:id/orders/{order_id}
+func WithPathTemplates(templates ...string) PathNormalizerOption {
+	return func(n *PathNormalizer) {
+		for _, t := range templates {
+			n.templates = append(n.templates, parsePathTemplate(t))
+		}
+	}
+}
+
+// WithMaxCardinality 设置不同结果的数量上限，超出后新结果返回 overflow（为空时使用 DefaultOverflowPath），<=0 表示不限制
+func WithMaxCardinality(max int, overflow string) PathNormalizerOption {
+	return func(n *PathNormalizer) {
+		n.maxPaths = max
+		if overflow != "" {
+			n.overflowPath = overflow
+		}
+	}
+}
+
+// NewPathNormalizer 创建路径标准化器
+func NewPathNormalizer(opts ...PathNormalizerOption) *PathNormalizer {
+	n := &PathNormalizer{
+		patterns:     DefaultPathPatterns(),
+		overflowPath: DefaultOverflowPath,
+		seen:         make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// AddTemplate 运行时注册路由模板
+func (n *PathNormalizer) AddTemplate(template string) {
+	t := parsePathTemplate(template)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.templates = append(n.templates, t)
+}
+
+// Normalize 标准化路径，忽略查询参数，合并连续斜杠并去掉末尾斜杠
+func (n *PathNormalizer) Normalize(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return path
+	}
+	parts := splitPath(path)
+	if len(parts) == 0 {
+		return "/"
+	}
+
+	n.mu.RLock()
+	result, ok := n.matchTemplate(parts)
+	n.mu.RUnlock()
+	if !ok {
+		for i, part := range parts {
+			for _, p := range n.patterns {
+				if p.Match(part) {
+					parts[i] = p.Placeholder
+					break
+				}
+			}
+		}
+		result = "/" + strings.Join(parts, "/")
+	}
+	return n.limit(result)
+}
+
+// Cardinality 返回已产生的不同结果数量（仅在设置基数上限时统计）
+func (n *PathNormalizer) Cardinality() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return len(n.seen)
+}
+
+// matchTemplate 按注册顺序匹配路由模板，调用方需持有读锁
+func (n *PathNormalizer) matchTemplate(parts []string) (string, bool) {
+	for _, t := range n.templates {
+		if t.match(parts) {
+			return t.raw, true
+		}
+	}
+	return "", false
+}
+
+// limit 应用基数上限
+func (n *PathNormalizer) limit(result string) string {
+	if n.maxPaths <= 0 {
+		return result
+	}
+	n.mu.RLock()
+	_, ok := n.seen[result]
+	n.mu.RUnlock()
+	if ok {
+		return result
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.seen[result]; ok {
+		return result
+	}
+	if len(n.seen) >= n.maxPaths {
+		return n.overflowPath
+	}
+	n.seen[result] = struct{}{}
+	return result
+}
+
+// pathTemplate 解析后的路由模板，params[i] 为 true 表示第 i 段是参数
+type pathTemplate struct {
+	raw      string
+	segments []string
+	params   []bool
+}
+
+func parsePathTemplate(template string) pathTemplate {
+	segments := splitPath(template)
+	t := pathTemplate{raw: "/" + strings.Join(segments, "/"), segments: segments, params: make([]bool, len(segments))}
+	for i, s := range segments {
+		t.params[i] = s == "*" || strings.HasPrefix(s, ":") || (strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"))
+	}
+	return t
+}
+
+func (t pathTemplate) match(parts []string) bool {
+	if len(parts) != len(t.segments) {
+		return false
+	}
+	for i, part := range parts {
+		if !t.params[i] && part != t.segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath 按斜杠分割路径并过滤空段
+func splitPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func isIntSegment(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isHexChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHexChar(s[i]) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isUUIDSegment(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexChar(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isObjectIDSegment(s string) bool {
+	return len(s) == 24 && isHexString(s)
+}
+
+func isHexSegment(s string) bool {
+	return len(s) >= 16 && isHexString(s)
+}
+
+// isULIDSegment Crockford Base32（不含 I、L、O、U），首字符不大于 7
+func isULIDSegment(s string) bool {
+	if len(s) != 26 || s[0] < '0' || s[0] > '7' {
+		return false
+	}
+	hasLetter := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'A' && c <= 'Z' && c != 'I' && c != 'L' && c != 'O' && c != 'U':
+			hasLetter = true
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+func isEmailSegment(s string) bool {
+	at := strings.Index(s, "@")
+	width := 1
+	if at < 0 {
+		at = strings.Index(strings.ToLower(s), "%40")
+		width = 3
+	}
+	if at <= 0 {
+		return false
+	}
+	domain := s[at+width:]
+	dot := strings.LastIndex(domain, ".")
+	return dot > 0 && dot < len(domain)-1 && !strings.ContainsAny(domain, "@")
+}
+
+func isBase64Segment(s string) bool {
+	if len(s) < 32 {
+		return false
+	}
+	jwt := strings.HasPrefix(s, "eyJ") && strings.Count(s, ".") == 2
+	if !jwt && !strings.ContainsAny(s, "-_+=") {
+		return false
+	}
+	var hasUpper, hasLower, hasDigit bool
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c == '+' || c == '-' || c == '_' || c == '.':
+		case c == '=':
+			if strings.TrimRight(s[i:], "=") != "" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return hasUpper && hasLower && hasDigit
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathNormalizer(t *testing.T) {
+	t.Run("TC01: 默认规则", func(t *testing.T) {
+		n := NewPathNormalizer()
+		tests := map[string]string{
+			"":                            "",
+			"/":                           "/",
+			"//api//v1/users/":            "/api/v1/users",
+			"/api/v1/user/123/orders/456": "/api/v1/user/:id/orders/:id",
+			"/api/v1/user/-1?page=2":      "/api/v1/user/:id",
+			"/api/v1/user/user-123":       "/api/v1/user/user-123",
+			"/files/550e8400-e29b-41d4-a716-446655440000":     "/files/:uuid",
+			"/docs/507f1f77bcf86cd799439011":                  "/docs/:oid",
+			"/events/01ARZ3NDEKTSV4RRFFQ69G5FAV":              "/events/:ulid",
+			"/blobs/9e107d9d372bb6826bd81d3542a419d6":         "/blobs/:hex",
+			"/users/alice@example.com/profile":                "/users/:email/profile",
+			"/users/alice%40example.com":                      "/users/:email",
+			"/reset/eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.abc": "/reset/eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.abc",
+			"/api/v1/getUserProfileSettings2/x":               "/api/v1/getUserProfileSettings2/x",
+			"/api/v2/oauth2CallbackHandlerV2":                 "/api/v2/oauth2CallbackHandlerV2",
+			"/settings/notification-preferences-2024":         "/settings/notification-preferences-2024",
+			"/api/v2/authorization/orn/policy":                "/api/v2/authorization/orn/policy",
+		}
+		for in, want := range tests {
+			assert.Equal(t, want, n.Normalize(in), in)
+		}
+	})
+
+	t.Run("TC02: 自定义规则", func(t *testing.T) {
+		sku := PathPattern{Name: "sku", Placeholder: ":sku", Match: func(s string) bool { return strings.HasPrefix(s, "SKU-") }}
+		n := NewPathNormalizer(WithExtraPathPatterns(sku))
+		assert.Equal(t, "/items/:sku/stock/:id", n.Normalize("/items/SKU-9/stock/3"))
+
+		n = NewPathNormalizer(WithPathPatterns(UUIDPattern))
+		assert.Equal(t, "/items/3", n.Normalize("/items/3"))
+
+		n = NewPathNormalizer(WithExtraPathPatterns(Base64Pattern))
+		for in, want := range map[string]string{
+			"/reset/eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.abc":        "/reset/:token",
+			"/invite/Qm9vdHN0cmFwVG9rZW4xMjM0NTY3ODkw_Zx-Y2":         "/invite/:token",
+			"/api/v1/getUserProfileSettings2/x":                      "/api/v1/getUserProfileSettings2/x",
+			"/api/v2/oauth2CallbackHandlerV2":                        "/api/v2/oauth2CallbackHandlerV2",
+			"/api/v2/getAllUserNotificationPreferencesForTenant2024": "/api/v2/getAllUserNotificationPreferencesForTenant2024",
+			"/api/short-Token_1":                                     "/api/short-Token_1",
+		} {
+			assert.Equal(t, want, n.Normalize(in), in)
+		}
+	})
+
+	t.Run("TC03: 路由模板", func(t *testing.T) {
+		n := NewPathNormalizer(WithPathTemplates("/api/v1/users/{user_id}/orders/:order_id", "/static/*"))
+		n.AddTemplate("/api/v1/users/me")
+		assert.Equal(t, "/api/v1/users/{user_id}/orders/:order_id", n.Normalize("/api/v1/users/alice/orders/A-1"))
+		assert.Equal(t, "/static/*", n.Normalize("/static/app.js"))
+		assert.Equal(t, "/api/v1/users/me", n.Normalize("/api/v1/users/me/"))
+		// 未注册模板的路径回退到规则替换
+		assert.Equal(t, "/api/v1/users/:id", n.Normalize("/api/v1/users/7"))
+	})
+
+	t.Run("TC04: 基数上限", func(t *testing.T) {
+		n := NewPathNormalizer(WithMaxCardinality(2, ""))
+		assert.Equal(t, "/a", n.Normalize("/a"))
+		assert.Equal(t, "/b/:id", n.Normalize("/b/1"))
+		assert.Equal(t, "/b/:id", n.Normalize("/b/2"))
+		assert.Equal(t, DefaultOverflowPath, n.Normalize("/c"))
+		assert.Equal(t, "/a", n.Normalize("/a"))
+		assert.Equal(t, 2, n.Cardinality())
+
+		n = NewPathNormalizer(WithMaxCardinality(10, "/other"))
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				n.Normalize(fmt.Sprintf("/p%d", i))
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 10, n.Cardinality())
+		assert.Equal(t, "/other", n.Normalize("/new"))
+	})
+}
+
+func BenchmarkPathNormalizer(b *testing.B) {
+	n := NewPathNormalizer()
+	for i := 0; i < b.N; i++ {
+		n.Normalize("/api/v1/users/123/orders/550e8400-e29b-41d4-a716-446655440000")
+	}
+}
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// PrefixSet 基于二叉前缀树的 CIDR 集合，查询耗时只与地址位数相关，适合上万条网段的匹配
+// 构建完成后可并发查询，但 Add 与查询不能并发进行
+type PrefixSet struct {
+	v4, v6 *prefixNode
+	size   int
+}
+
+type prefixNode struct {
+	children [2]*prefixNode
+	terminal bool
+}
+
+// NewPrefixSet 使用给定网段创建集合
+func NewPrefixSet(prefixes ...netip.Prefix) *PrefixSet {
+	s := &PrefixSet{v4: &prefixNode{}, v6: &prefixNode{}}
+	for _, p := range prefixes {
+		s.Add(p)
+	}
+	return s
+}
+
+// ParsePrefixSet 解析 CIDR 或单个 IP 列表创建集合，忽略空行和 # 开头的注释
+func ParsePrefixSet(entries []string) (*PrefixSet, error) {
+	s := NewPrefixSet()
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		p, err := ParsePrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		s.Add(p)
+	}
+	return s, nil
+}
+
+// Add 添加网段，IPv4 映射的 IPv6 网段按 IPv4 处理
+func (s *PrefixSet) Add(p netip.Prefix) {
+	if !p.IsValid() {
+		return
+	}
+	if p.Addr().Is4In6() && p.Bits() >= 96 {
+		p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	p = p.Masked()
+
+	node, bits := s.root(p.Addr())
+	for i := 0; i < p.Bits(); i++ {
+		if node.terminal {
+			// 已被更短的网段覆盖
+			return
+		}
+		b := bitAt(bits, i)
+		if node.children[b] == nil {
+			node.children[b] = &prefixNode{}
+		}
+		node = node.children[b]
+	}
+	if !node.terminal {
+		s.size -= node.count()
+		node.terminal = true
+		node.children = [2]*prefixNode{}
+		s.size++
+	}
+}
+
+// count 统计子树中的网段数量
+func (n *prefixNode) count() int {
+	if n == nil {
+		return 0
+	}
+	if n.terminal {
+		return 1
+	}
+	return n.children[0].count() + n.children[1].count()
+}
+
+// Contains 判断地址是否属于集合中的任一网段
+func (s *PrefixSet) Contains(addr netip.Addr) bool {
+	if s == nil || !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+	node, bits := s.root(addr)
+	for i := 0; node != nil; i++ {
+		if node.terminal {
+			return true
+		}
+		if i >= addr.BitLen() {
+			return false
+		}
+		node = node.children[bitAt(bits, i)]
+	}
+	return false
+}
+
+// Len 返回集合中的网段数量（被更短网段覆盖的网段不计入）
+func (s *PrefixSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return s.size
+}
+
+func (s *PrefixSet) root(addr netip.Addr) (*prefixNode, [16]byte) {
+	if addr.Is4() {
+		a := addr.As4()
+		var bits [16]byte
+		copy(bits[:], a[:])
+		return s.v4, bits
+	}
+	return s.v6, addr.As16()
+}
+
+// bitAt 返回第 i 位（从高位开始）
+func bitAt(bits [16]byte, i int) int {
+	return int(bits[i/8]>>(7-uint(i%8))) & 1
+}
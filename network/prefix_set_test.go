@@ -0,0 +1,60 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixSet(t *testing.T) {
+	s, err := ParsePrefixSet([]string{
+		"# office",
+		"10.1.0.0/16",
+		"192.168.1.10",
+		"",
+		"2001:db8::/48",
+		"::ffff:172.16.0.0/108",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, s.Len())
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"10.2.0.1", false},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"::ffff:10.1.0.1", true},
+		{"172.16.5.5", true},
+		{"2001:db8:0:1::1", true},
+		{"2001:db8:1::1", false},
+		{"fe80::1%eth0", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, s.Contains(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+
+	// 更短的网段覆盖已有网段
+	s.Add(netip.MustParsePrefix("10.0.0.0/8"))
+	assert.Equal(t, 4, s.Len())
+	assert.True(t, s.Contains(netip.MustParseAddr("10.2.0.1")))
+
+	_, err = ParsePrefixSet([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+func BenchmarkPrefixSetContains(b *testing.B) {
+	s := NewPrefixSet()
+	for i := 0; i < 50000; i++ {
+		s.Add(netip.MustParsePrefix(fmt.Sprintf("%d.%d.%d.0/24", 10+i/65536, (i/256)%256, i%256)))
+	}
+	addr := netip.MustParseAddr("10.100.200.1")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Contains(addr)
+	}
+}
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyStyle 嵌套字段的 key 格式
+type KeyStyle int
+
+const (
+	// DotKeys 使用点号连接嵌套字段，如 user.name、items[0].id
+	DotKeys KeyStyle = iota
+	// BracketKeys 使用方括号连接嵌套字段，如 user[name]、items[0][id]
+	BracketKeys
+)
+
+// QueryOption 查询参数编解码的配置项
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	keyStyle KeyStyle
+	tagName  string
+}
+
+func newQueryOptions(opts []QueryOption) *queryOptions {
+	o := &queryOptions{keyStyle: DotKeys, tagName: "form"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithKeyStyle 设置嵌套字段的 key 格式，默认 DotKeys
+func WithKeyStyle(style KeyStyle) QueryOption {
+	return func(o *queryOptions) {
+		o.keyStyle = style
+	}
+}
+
+// WithTagName 设置读取参数名的结构体 tag，默认 form
+func WithTagName(name string) QueryOption {
+	return func(o *queryOptions) {
+		o.tagName = name
+	}
+}
+
+// childKey 拼接嵌套字段的 key
+func (o *queryOptions) childKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if o.keyStyle == BracketKeys {
+		return prefix + "[" + name + "]"
+	}
+	return prefix + "." + name
+}
+
+// indexKey 拼接切片元素的 key
+func indexKey(prefix string, i int) string {
+	return prefix + "[" + strconv.Itoa(i) + "]"
+}
+
+// queryField 结构体字段的编解码配置，tag 含义与 gin 的 form 绑定保持一致：
+// form:"name,omitempty"、collection_format:"multi|csv|ssv|tsv|pipes"、
+// time_format:"2006-01-02|unix|unixmilli|unixmicro|unixnano"、time_utc:"1"、time_location:"Asia/Shanghai"
+type queryField struct {
+	name             string
+	omitEmpty        bool
+	collectionFormat string
+	timeFormat       string
+	timeUTC          bool
+	timeLocation     string
+}
+
+// parseQueryField 解析字段 tag，ok 为 false 表示字段不参与编解码
+func parseQueryField(f reflect.StructField, tagName string) (qf queryField, ok bool) {
+	tag, has := f.Tag.Lookup(tagName)
+	if !has || tag == "-" {
+		return qf, false
+	}
+	name, rest, _ := strings.Cut(tag, ",")
+	qf.name = name
+	for _, flag := range strings.Split(rest, ",") {
+		if flag == "omitempty" {
+			qf.omitEmpty = true
+		}
+	}
+	if qf.name == "" {
+		qf.name = f.Name
+	}
+	qf.collectionFormat = f.Tag.Get("collection_format")
+	qf.timeFormat = f.Tag.Get("time_format")
+	qf.timeUTC, _ = strconv.ParseBool(f.Tag.Get("time_utc"))
+	qf.timeLocation = f.Tag.Get("time_location")
+	return qf, true
+}
+
+// separator 返回集合格式对应的分隔符，multi（默认）返回空字符串表示重复 key
+func (qf queryField) separator() string {
+	switch qf.collectionFormat {
+	case "csv":
+		return ","
+	case "ssv":
+		return " "
+	case "tsv":
+		return "\t"
+	case "pipes":
+		return "|"
+	default:
+		return ""
+	}
+}
+
+// isEmbeddedStruct 判断是否为没有 tag 的匿名嵌入结构体（字段会被提升到外层）
+func isEmbeddedStruct(f reflect.StructField, tagName string) bool {
+	if !f.Anonymous {
+		return false
+	}
+	if _, has := f.Tag.Lookup(tagName); has {
+		return false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
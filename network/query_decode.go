@@ -0,0 +1,430 @@
+package network
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gtime "github.com/hargeek/gopkg/time"
+)
+
+// FieldError 单个字段的解码错误
+type FieldError struct {
+	Field string // 结构体字段路径，如 Items[0].ID
+	Key   string // 查询参数名
+	Value string // 原始值
+	Err   error
+}
+
+// Error 实现 error 接口
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("字段 %s（参数 %s=%q）: %v", e.Field, e.Key, e.Value, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// QueryDecodeErrors ParseQueryParams 聚合的字段错误
+type QueryDecodeErrors []*FieldError
+
+// Error 实现 error 接口
+func (e QueryDecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "查询参数解析失败: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap 返回全部字段错误，便于 errors.As 取出 *FieldError
+func (e QueryDecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// ParseQueryParams 将查询参数解析到 dst 指向的结构体，是 ConvertToQueryParams 的逆操作，使用相同的 tag 和 QueryOption
+// 支持整数、浮点、布尔、time.Duration（使用本库 time.ParseDuration，支持 "1d2h"）、time.Time、
+// encoding.TextUnmarshaler（如 decimal.Decimal）、切片、map[string]T 和嵌套结构体；
+// 数值和布尔字段的空字符串解析为零值；所有字段错误汇总为 QueryDecodeErrors 返回，不会在第一个错误处中断
+func ParseQueryParams(values url.Values, dst interface{}, opts ...QueryOption) error {
+	val := reflect.ValueOf(dst)
+	if !val.IsValid() || val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("参数必须是非 nil 的结构体指针")
+	}
+	val = val.Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("参数必须是非 nil 的结构体指针")
+	}
+
+	d := &queryDecoder{opts: newQueryOptions(opts), values: values}
+	d.decodeStruct("", "", val)
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+	return nil
+}
+
+type queryDecoder struct {
+	opts   *queryOptions
+	values url.Values
+	errs   QueryDecodeErrors
+}
+
+func (d *queryDecoder) addError(path, key, value string, err error) {
+	d.errs = append(d.errs, &FieldError{Field: path, Key: key, Value: value, Err: err})
+}
+
+// decodeStruct 遍历结构体字段
+func (d *queryDecoder) decodeStruct(prefix, path string, val reflect.Value) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := val.Field(i)
+
+		if isEmbeddedStruct(field, d.opts.tagName) {
+			if fieldValue.Kind() == reflect.Ptr {
+				if !field.IsExported() || !d.hasStructKeys(prefix, field.Type.Elem()) {
+					continue
+				}
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			d.decodeStruct(prefix, path, fieldValue)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		qf, ok := parseQueryField(field, d.opts.tagName)
+		if !ok {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		d.decodeValue(d.opts.childKey(prefix, qf.name), fieldPath, fieldValue, qf)
+	}
+}
+
+// decodeValue 解码单个字段，参数中没有对应 key 时保持原值
+func (d *queryDecoder) decodeValue(key, path string, v reflect.Value, qf queryField) {
+	if !d.present(key) {
+		return
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.decodeValue(key, path, v.Elem(), qf)
+		return
+	}
+
+	if isLeafType(v.Type()) {
+		raw := d.values.Get(key)
+		if err := setFromString(v, raw, qf); err != nil {
+			d.addError(path, key, raw, err)
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		d.decodeStruct(key, path, v)
+	case reflect.Slice:
+		d.decodeSlice(key, path, v, qf)
+	case reflect.Map:
+		d.decodeMap(key, path, v, qf)
+	default:
+		d.addError(path, key, d.values.Get(key), fmt.Errorf("不支持的字段类型: %s", v.Type()))
+	}
+}
+
+// decodeSlice 解码切片：标量元素来自重复 key 或按 collection_format 拆分，结构体元素来自带下标的 key
+func (d *queryDecoder) decodeSlice(key, path string, v reflect.Value, qf queryField) {
+	elemType := v.Type().Elem()
+	if isLeafType(elemType) {
+		var raws []string
+		for _, raw := range d.values[key] {
+			if sep := qf.separator(); sep != "" {
+				if raw == "" {
+					continue
+				}
+				raws = append(raws, strings.Split(raw, sep)...)
+			} else {
+				raws = append(raws, raw)
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(raws), len(raws))
+		for i, raw := range raws {
+			if err := setFromString(slice.Index(i), raw, qf); err != nil {
+				d.addError(fmt.Sprintf("%s[%d]", path, i), key, raw, err)
+			}
+		}
+		v.Set(slice)
+		return
+	}
+
+	indices := d.indices(key)
+	if len(indices) == 0 {
+		return
+	}
+	slice := reflect.MakeSlice(v.Type(), indices[len(indices)-1]+1, indices[len(indices)-1]+1)
+	elemField := qf
+	for _, i := range indices {
+		d.decodeValue(indexKey(key, i), fmt.Sprintf("%s[%d]", path, i), slice.Index(i), elemField)
+	}
+	v.Set(slice)
+}
+
+// decodeMap 解码 key 为字符串、值为标量的 map
+func (d *queryDecoder) decodeMap(key, path string, v reflect.Value, qf queryField) {
+	if v.Type().Key().Kind() != reflect.String || !isLeafType(v.Type().Elem()) {
+		d.addError(path, key, "", fmt.Errorf("不支持的字段类型: %s", v.Type()))
+		return
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	for _, name := range d.mapKeys(key) {
+		fullKey := d.opts.childKey(key, name)
+		raw := d.values.Get(fullKey)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := setFromString(elem, raw, qf); err != nil {
+			d.addError(path+"["+name+"]", fullKey, raw, err)
+			continue
+		}
+		v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+	}
+}
+
+// present 判断参数中是否有 key 本身或其嵌套的 key
+func (d *queryDecoder) present(key string) bool {
+	if _, ok := d.values[key]; ok {
+		return true
+	}
+	for k := range d.values {
+		if strings.HasPrefix(k, key) && len(k) > len(key) && (k[len(key)] == '.' || k[len(key)] == '[') {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStructKeys 判断指针类型的匿名嵌入结构体是否有任一字段出现在参数中
+func (d *queryDecoder) hasStructKeys(prefix string, t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isEmbeddedStruct(field, d.opts.tagName) {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if d.hasStructKeys(prefix, ft) {
+				return true
+			}
+			continue
+		}
+		if qf, ok := parseQueryField(field, d.opts.tagName); ok && field.IsExported() && d.present(d.opts.childKey(prefix, qf.name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// indices 返回 key[i] 形式出现的全部下标（升序）
+func (d *queryDecoder) indices(key string) []int {
+	seen := make(map[int]bool)
+	for k := range d.values {
+		rest, ok := strings.CutPrefix(k, key+"[")
+		if !ok {
+			continue
+		}
+		end := strings.IndexByte(rest, ']')
+		if end <= 0 {
+			continue
+		}
+		if i, err := strconv.Atoi(rest[:end]); err == nil && i >= 0 && i < maxSliceIndex {
+			seen[i] = true
+		}
+	}
+	indices := make([]int, 0, len(seen))
+	for i := range seen {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// maxSliceIndex 限制切片下标，防止恶意参数导致分配超大切片
+const maxSliceIndex = 10000
+
+// mapKeys 返回 map 字段在参数中出现的全部子 key
+func (d *queryDecoder) mapKeys(key string) []string {
+	var names []string
+	for k := range d.values {
+		var name string
+		if d.opts.keyStyle == BracketKeys {
+			rest, ok := strings.CutPrefix(k, key+"[")
+			if !ok || !strings.HasSuffix(rest, "]") {
+				continue
+			}
+			name = strings.TrimSuffix(rest, "]")
+		} else {
+			rest, ok := strings.CutPrefix(k, key+".")
+			if !ok {
+				continue
+			}
+			name = rest
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isLeafType 判断类型是否按单个字符串值解析
+func isLeafType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == durationType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// setFromString 将字符串解析到 v
+func setFromString(v reflect.Value, raw string, qf queryField) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setFromString(v.Elem(), raw, qf)
+	}
+
+	switch v.Type() {
+	case timeType:
+		if raw == "" {
+			return nil
+		}
+		t, err := parseTime(raw, qf)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		if raw == "" {
+			return nil
+		}
+		dur, err := gtime.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(dur))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if raw == "" && v.Kind() != reflect.String && v.Kind() != reflect.Slice {
+		v.SetZero()
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Slice:
+		v.SetBytes([]byte(raw))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("不支持的字段类型: %s", v.Type())
+	}
+	return nil
+}
+
+// parseTime 按 time_format、time_utc、time_location 解析时间，默认 RFC3339，未指定时区时使用本地时区
+func parseTime(raw string, qf queryField) (time.Time, error) {
+	loc := time.Local
+	if qf.timeUTC {
+		loc = time.UTC
+	} else if qf.timeLocation != "" {
+		l, err := time.LoadLocation(qf.timeLocation)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = l
+	}
+
+	switch qf.timeFormat {
+	case "unix", "unixmilli", "unixmicro", "unixnano":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		var t time.Time
+		switch qf.timeFormat {
+		case "unix":
+			t = time.Unix(n, 0)
+		case "unixmilli":
+			t = time.UnixMilli(n)
+		case "unixmicro":
+			t = time.UnixMicro(n)
+		default:
+			t = time.Unix(0, n)
+		}
+		return t.In(loc), nil
+	case "":
+		return time.ParseInLocation(time.RFC3339, raw, loc)
+	default:
+		return time.ParseInLocation(qf.timeFormat, raw, loc)
+	}
+}
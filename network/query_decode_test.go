@@ -0,0 +1,116 @@
+package network
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type queryPage struct {
+	Page int `form:"page"`
+	Size int `form:"size"`
+}
+
+type queryItem struct {
+	ID   int    `form:"id"`
+	Name string `form:"name"`
+}
+
+type queryRequest struct {
+	queryPage
+	Keyword *string           `form:"keyword"`
+	Active  bool              `form:"active"`
+	Score   float64           `form:"score"`
+	Tags    []string          `form:"tag"`
+	IDs     []uint            `form:"ids" collection_format:"csv"`
+	Items   []queryItem       `form:"items"`
+	Owner   *queryItem        `form:"owner"`
+	Labels  map[string]string `form:"labels"`
+	Since   time.Time         `form:"since" time_format:"2006-01-02" time_utc:"1"`
+	Until   time.Time         `form:"until" time_format:"unix"`
+	Timeout time.Duration     `form:"timeout"`
+	Amount  decimal.Decimal   `form:"amount"`
+}
+
+func TestParseQueryParams(t *testing.T) {
+	t.Run("TC01: 类型转换", func(t *testing.T) {
+		values, _ := url.ParseQuery("page=2&size=&keyword=go&active=true&score=9.5&tag=a&tag=b&ids=1,2,3" +
+			"&items[0].id=7&items[1].id=8&items[1].name=x&owner.name=bob&labels.env=prod" +
+			"&since=2024-11-07&until=1700000000&timeout=1d2h&amount=12.50")
+		var req queryRequest
+		assert.NoError(t, ParseQueryParams(values, &req))
+		assert.Equal(t, 2, req.Page)
+		assert.Equal(t, 0, req.Size)
+		assert.Equal(t, "go", *req.Keyword)
+		assert.True(t, req.Active)
+		assert.Equal(t, 9.5, req.Score)
+		assert.Equal(t, []string{"a", "b"}, req.Tags)
+		assert.Equal(t, []uint{1, 2, 3}, req.IDs)
+		assert.Equal(t, []queryItem{{ID: 7}, {ID: 8, Name: "x"}}, req.Items)
+		assert.Equal(t, &queryItem{Name: "bob"}, req.Owner)
+		assert.Equal(t, map[string]string{"env": "prod"}, req.Labels)
+		assert.Equal(t, time.Date(2024, 11, 7, 0, 0, 0, 0, time.UTC), req.Since)
+		assert.Equal(t, int64(1700000000), req.Until.Unix())
+		assert.Equal(t, 26*time.Hour, req.Timeout)
+		assert.True(t, decimal.RequireFromString("12.5").Equal(req.Amount))
+	})
+
+	t.Run("TC02: 聚合字段错误", func(t *testing.T) {
+		values, _ := url.ParseQuery("page=abc&active=maybe&items[0].id=x&since=yesterday&timeout=bad")
+		var req queryRequest
+		err := ParseQueryParams(values, &req)
+		var errs QueryDecodeErrors
+		assert.True(t, errors.As(err, &errs))
+		assert.Len(t, errs, 5)
+		var fe *FieldError
+		assert.True(t, errors.As(err, &fe))
+		fields := map[string]string{}
+		for _, e := range errs {
+			fields[e.Field] = e.Key
+		}
+		assert.Equal(t, map[string]string{
+			"Page":        "page",
+			"Active":      "active",
+			"Items[0].ID": "items[0].id",
+			"Since":       "since",
+			"Timeout":     "timeout",
+		}, fields)
+	})
+
+	t.Run("TC03: 与 ConvertToQueryParams 往返一致", func(t *testing.T) {
+		keyword := "go"
+		input := queryRequest{
+			queryPage: queryPage{Page: 3, Size: 20},
+			Keyword:   &keyword,
+			Tags:      []string{"a"},
+			IDs:       []uint{4, 5},
+			Items:     []queryItem{{ID: 1, Name: "n1"}},
+			Owner:     &queryItem{ID: 9},
+			Labels:    map[string]string{"k": "v"},
+			Since:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Until:     time.Unix(1700000000, 0),
+			Timeout:   90 * time.Second,
+			Amount:    decimal.RequireFromString("3.25"),
+		}
+		for _, style := range []KeyStyle{DotKeys, BracketKeys} {
+			values, err := ConvertToQueryParams(input, WithKeyStyle(style))
+			assert.NoError(t, err)
+			var output queryRequest
+			assert.NoError(t, ParseQueryParams(values, &output, WithKeyStyle(style)))
+			assert.True(t, input.Amount.Equal(output.Amount))
+			assert.True(t, input.Until.Equal(output.Until))
+			output.Amount, output.Until = input.Amount, input.Until
+			assert.Equal(t, input, output)
+		}
+	})
+
+	t.Run("TC04: 非结构体指针", func(t *testing.T) {
+		var req queryRequest
+		assert.Error(t, ParseQueryParams(url.Values{}, req))
+		assert.Error(t, ParseQueryParams(url.Values{}, nil))
+	})
+}
@@ -1,48 +1,270 @@
 package network
 
 import (
+	"encoding"
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// ConvertToQueryParams 将结构体转换为查询参数，参数名为form tag
-func ConvertToQueryParams(params interface{}) (url.Values, error) {
-	v := url.Values{}
-
-	// 检查是否为指针
+// ConvertToQueryParams 将结构体（或结构体指针）转换为查询参数，参数名为form tag
+// 支持的 tag 见 queryField：omitempty 省略零值，nil 指针总是省略；
+// 切片默认重复 key，collection_format 可改为逗号等分隔；嵌套结构体、map 按 KeyStyle 生成 key；
+// 无 tag 的匿名嵌入结构体字段提升到外层；time.Time 按 time_format 格式化，time.Duration 使用 String()，
+// 其他实现了 encoding.TextMarshaler 的类型使用 MarshalText
+func ConvertToQueryParams(params interface{}, opts ...QueryOption) (url.Values, error) {
 	val := reflect.ValueOf(params)
-	if val.Kind() != reflect.Ptr {
-		return nil, fmt.Errorf("参数必须是结构体指针")
-	}
-
-	// 检查指针是否为 nil
-	if val.IsNil() {
+	if !val.IsValid() {
 		return nil, fmt.Errorf("参数不能为 nil")
 	}
 
-	// 获取指针指向的实际值
-	elem := val.Elem()
+	// 指针取其指向的值
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, fmt.Errorf("参数不能为 nil")
+		}
+		val = val.Elem()
+	}
 
 	// 检查是否为结构体
-	if elem.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("参数必须是结构体指针")
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("参数必须是结构体或结构体指针")
+	}
+
+	e := &queryEncoder{opts: newQueryOptions(opts), values: url.Values{}}
+	if err := e.encodeStruct("", val); err != nil {
+		return nil, err
 	}
+	return e.values, nil
+}
 
-	// 遍历结构体字段
-	for i := 0; i < elem.NumField(); i++ {
-		field := elem.Type().Field(i)
-		fieldValue := elem.Field(i)
-		tag := field.Tag.Get("form")
+type queryEncoder struct {
+	opts   *queryOptions
+	values url.Values
+}
+
+// encodeStruct 遍历结构体字段
+func (e *queryEncoder) encodeStruct(prefix string, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := val.Field(i)
 
-		if tag != "" {
-			// 如果是结构体类型，返回错误
-			if fieldValue.Kind() == reflect.Struct {
-				return nil, fmt.Errorf("不支持嵌套结构体字段: %s", field.Name)
+		if isEmbeddedStruct(field, e.opts.tagName) {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
 			}
-			v.Set(tag, fmt.Sprintf("%v", fieldValue.Interface()))
+			if err := e.encodeStruct(prefix, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		qf, ok := parseQueryField(field, e.opts.tagName)
+		if !ok {
+			continue
+		}
+		if err := e.encodeValue(e.opts.childKey(prefix, qf.name), fieldValue, qf); err != nil {
+			return fmt.Errorf("字段 %s: %w", field.Name, err)
 		}
 	}
+	return nil
+}
 
-	return v, nil
+// encodeValue 编码单个值
+func (e *queryEncoder) encodeValue(key string, v reflect.Value, qf queryField) error {
+	if qf.omitEmpty && isEmptyValue(v) {
+		return nil
+	}
+	v, ok := indirectValue(v)
+	if !ok {
+		return nil
+	}
+
+	if s, ok, err := formatSpecial(v, qf); ok || err != nil {
+		if err == nil {
+			e.values.Add(key, s)
+		}
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(key, v)
+	case reflect.Map:
+		return e.encodeMap(key, v, qf)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			e.values.Add(key, string(v.Bytes()))
+			return nil
+		}
+		return e.encodeSlice(key, v, qf)
+	default:
+		s, err := formatScalar(v)
+		if err != nil {
+			return err
+		}
+		e.values.Add(key, s)
+		return nil
+	}
+}
+
+// encodeSlice 编码切片：标量元素按 collection_format 重复 key 或拼接，结构体元素使用带下标的 key
+func (e *queryEncoder) encodeSlice(key string, v reflect.Value, qf queryField) error {
+	sep := qf.separator()
+	var joined []string
+	for i := 0; i < v.Len(); i++ {
+		elem, ok := indirectValue(v.Index(i))
+		if !ok {
+			continue
+		}
+		s, ok, err := formatSpecial(elem, qf)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			switch elem.Kind() {
+			case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+				elemField := qf
+				elemField.omitEmpty = false
+				if err := e.encodeValue(indexKey(key, i), elem, elemField); err != nil {
+					return err
+				}
+				continue
+			}
+			if s, err = formatScalar(elem); err != nil {
+				return err
+			}
+		}
+		if sep == "" {
+			e.values.Add(key, s)
+		} else {
+			joined = append(joined, s)
+		}
+	}
+	if len(joined) > 0 {
+		e.values.Add(key, strings.Join(joined, sep))
+	}
+	return nil
+}
+
+// encodeMap 编码 map，key 按字典序输出
+func (e *queryEncoder) encodeMap(key string, v reflect.Value, qf queryField) error {
+	keys := make([]string, 0, v.Len())
+	index := make(map[string]reflect.Value, v.Len())
+	for _, k := range v.MapKeys() {
+		ks := fmt.Sprint(k.Interface())
+		keys = append(keys, ks)
+		index[ks] = v.MapIndex(k)
+	}
+	sort.Strings(keys)
+	elemField := qf
+	elemField.omitEmpty = false
+	for _, k := range keys {
+		if err := e.encodeValue(e.opts.childKey(key, k), index[k], elemField); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSpecial 格式化 time.Time、time.Duration 和 encoding.TextMarshaler，ok 为 false 表示不是这些类型
+func formatSpecial(v reflect.Value, qf queryField) (s string, ok bool, err error) {
+	switch v.Type() {
+	case timeType:
+		return formatTime(v.Interface().(time.Time), qf), true, nil
+	case durationType:
+		return time.Duration(v.Int()).String(), true, nil
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), true, err
+	}
+	if reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		// 指针接收者实现的 MarshalText，不可寻址时先拷贝一份
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		b, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), true, err
+	}
+	return "", false, nil
+}
+
+// formatTime 按 time_format、time_utc、time_location 格式化时间，默认 RFC3339
+func formatTime(t time.Time, qf queryField) string {
+	if qf.timeUTC {
+		t = t.UTC()
+	} else if qf.timeLocation != "" {
+		if loc, err := time.LoadLocation(qf.timeLocation); err == nil {
+			t = t.In(loc)
+		}
+	}
+	switch qf.timeFormat {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "unixmicro":
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case "unixnano":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	case "":
+		return t.Format(time.RFC3339)
+	default:
+		return t.Format(qf.timeFormat)
+	}
+}
+
+// formatScalar 格式化基本类型
+func formatScalar(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("不支持的字段类型: %s", v.Type())
+	}
+}
+
+// indirectValue 解引用指针和接口，nil 时返回 false
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// isEmptyValue 判断 omitempty 时是否省略
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
 }
@@ -2,7 +2,9 @@ package network
 
 import (
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -65,16 +67,21 @@ func TestConvertToQueryParams(t *testing.T) {
 		assert.Equal(t, "false", values.Get("bool"))
 	})
 
-	t.Run("TC05: 嵌套结构体（应返回错误）", func(t *testing.T) {
+	t.Run("TC05: 嵌套结构体", func(t *testing.T) {
 		type NestedStruct struct {
 			User struct {
 				Name string `form:"name"`
-			} `form:"user"` // 嵌套结构体字段应该返回错误
+			} `form:"user"`
 		}
 		input := &NestedStruct{}
-		_, err := ConvertToQueryParams(input)
-		assert.Error(t, err) // 期望返回错误
-		assert.Contains(t, err.Error(), "不支持嵌套结构体字段")
+		input.User.Name = "Bob"
+		values, err := ConvertToQueryParams(input)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bob", values.Get("user.name"))
+
+		values, err = ConvertToQueryParams(input, WithKeyStyle(BracketKeys))
+		assert.NoError(t, err)
+		assert.Equal(t, "Bob", values.Get("user[name]"))
 	})
 
 	t.Run("TC06: 无form标签的嵌套结构体（应被忽略）", func(t *testing.T) {
@@ -91,3 +98,80 @@ func TestConvertToQueryParams(t *testing.T) {
 		assert.Empty(t, values.Get("age")) // 嵌套字段不会被处理
 	})
 }
+
+func TestConvertToQueryParamsEncoder(t *testing.T) {
+	type Page struct {
+		Page int `form:"page,omitempty"`
+		Size int `form:"size,omitempty"`
+	}
+	type Item struct {
+		ID int `form:"id"`
+	}
+	type Request struct {
+		Page
+		Keyword  *string           `form:"keyword"`
+		Status   *int              `form:"status"`
+		Tags     []string          `form:"tag"`
+		IDs      []int             `form:"ids" collection_format:"csv"`
+		Items    []Item            `form:"items"`
+		Labels   map[string]string `form:"labels"`
+		Since    time.Time         `form:"since" time_format:"2006-01-02" time_utc:"1"`
+		Until    time.Time         `form:"until" time_format:"unix"`
+		Timeout  time.Duration     `form:"timeout"`
+		Amount   decimal.Decimal   `form:"amount"`
+		Empty    string            `form:"empty,omitempty"`
+		Ignored  string            `form:"-"`
+		internal string
+	}
+
+	keyword := "go"
+	input := Request{
+		Page:    Page{Page: 2},
+		Keyword: &keyword,
+		Tags:    []string{"a", "b"},
+		IDs:     []int{1, 2, 3},
+		Items:   []Item{{ID: 7}, {ID: 8}},
+		Labels:  map[string]string{"env": "prod"},
+		Since:   time.Date(2024, 11, 7, 12, 0, 0, 0, time.UTC),
+		Until:   time.Unix(1700000000, 0),
+		Timeout: 90 * time.Second,
+		Amount:  decimal.RequireFromString("12.50"),
+		Ignored: "x",
+	}
+
+	// 同时支持值和指针
+	for _, params := range []interface{}{input, &input} {
+		values, err := ConvertToQueryParams(params)
+		assert.NoError(t, err)
+		assert.Equal(t, "2", values.Get("page"))
+		assert.NotContains(t, values, "size")
+		assert.Equal(t, "go", values.Get("keyword"))
+		assert.NotContains(t, values, "status") // nil 指针不输出 <nil>
+		assert.Equal(t, []string{"a", "b"}, values["tag"])
+		assert.Equal(t, "1,2,3", values.Get("ids"))
+		assert.Equal(t, "7", values.Get("items[0].id"))
+		assert.Equal(t, "8", values.Get("items[1].id"))
+		assert.Equal(t, "prod", values.Get("labels.env"))
+		assert.Equal(t, "2024-11-07", values.Get("since"))
+		assert.Equal(t, "1700000000", values.Get("until"))
+		assert.Equal(t, "1m30s", values.Get("timeout"))
+		assert.Equal(t, "12.5", values.Get("amount"))
+		assert.NotContains(t, values, "empty")
+		assert.NotContains(t, values, "Ignored")
+	}
+
+	values, err := ConvertToQueryParams(input, WithKeyStyle(BracketKeys))
+	assert.NoError(t, err)
+	assert.Equal(t, "7", values.Get("items[0][id]"))
+	assert.Equal(t, "prod", values.Get("labels[env]"))
+
+	var nilPtr *Request
+	_, err = ConvertToQueryParams(nilPtr)
+	assert.Error(t, err)
+
+	type Unsupported struct {
+		Fn func() `form:"fn"`
+	}
+	_, err = ConvertToQueryParams(Unsupported{Fn: func() {}})
+	assert.Error(t, err)
+}
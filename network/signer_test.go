@@ -0,0 +1,86 @@
+package network
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLSigner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	keys := map[string][]byte{"k1": []byte("secret-1"), "k2": []byte("secret-2")}
+	signer, err := NewURLSigner("k2", keys)
+	assert.NoError(t, err)
+	signer.now = func() time.Time { return now }
+
+	signed, err := signer.Sign(http.MethodGet, "https://cdn.example.com/files/a b.zip?user=1", time.Hour)
+	assert.NoError(t, err)
+	u, _ := url.Parse(signed)
+	assert.Equal(t, "k2", u.Query().Get(SignKeyIDParam))
+	assert.Equal(t, "1700003600", u.Query().Get(SignExpiresParam))
+
+	t.Run("TC01: 校验通过", func(t *testing.T) {
+		assert.NoError(t, signer.Verify(http.MethodGet, signed))
+		assert.NoError(t, signer.Verify(http.MethodHead, signed))
+		// 主机不参与签名，路径中的 . 段规范化后一致
+		assert.NoError(t, signer.Verify(http.MethodGet, strings.Replace(signed, "cdn.example.com/files", "origin.internal/x/../files", 1)))
+
+		req := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(signed, "https://cdn.example.com"), nil)
+		assert.NoError(t, signer.VerifyRequest(req))
+
+		// 路径中等价的百分号编码规范化后一致
+		tilde, err := signer.Sign(http.MethodGet, "https://cdn.example.com/~alice/%e6%96%87.txt", time.Hour)
+		assert.NoError(t, err)
+		assert.NoError(t, signer.Verify(http.MethodGet, tilde))
+		assert.NoError(t, signer.Verify(http.MethodGet, strings.Replace(tilde, "/~alice/%e6%96%87", "/%7Ealice/%E6%96%87", 1)))
+		req = httptest.NewRequest(http.MethodGet, strings.Replace(strings.TrimPrefix(tilde, "https://cdn.example.com"), "~", "%7E", 1), nil)
+		assert.NoError(t, signer.VerifyRequest(req))
+	})
+
+	t.Run("TC02: 篡改与方法不符", func(t *testing.T) {
+		assert.True(t, errors.Is(signer.Verify(http.MethodPut, signed), ErrInvalidSignature))
+		assert.True(t, errors.Is(signer.Verify(http.MethodGet, strings.Replace(signed, "user=1", "user=2", 1)), ErrInvalidSignature))
+		assert.True(t, errors.Is(signer.Verify(http.MethodGet, strings.Replace(signed, "a%20b.zip", "c.zip", 1)), ErrInvalidSignature))
+		assert.True(t, errors.Is(signer.Verify(http.MethodGet, strings.Replace(signed, "1700003600", "1800000000", 1)), ErrInvalidSignature))
+		assert.True(t, errors.Is(signer.Verify(http.MethodGet, "https://cdn.example.com/files/a.zip"), ErrInvalidSignature))
+	})
+
+	t.Run("TC03: 过期", func(t *testing.T) {
+		signer.now = func() time.Time { return now.Add(time.Hour) }
+		defer func() { signer.now = func() time.Time { return now } }()
+		assert.True(t, errors.Is(signer.Verify(http.MethodGet, signed), ErrSignatureExpired))
+	})
+
+	t.Run("TC04: 密钥轮换", func(t *testing.T) {
+		old, err := NewURLSigner("k1", keys)
+		assert.NoError(t, err)
+		old.now = signer.now
+		oldLink, err := old.Sign(http.MethodGet, "/download", time.Minute)
+		assert.NoError(t, err)
+		assert.NoError(t, signer.Verify(http.MethodGet, oldLink))
+
+		rotated, err := NewURLSigner("k3", map[string][]byte{"k3": []byte("secret-3")})
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(rotated.Verify(http.MethodGet, oldLink), ErrUnknownSignKey))
+
+		// 重新签名会替换已有的签名参数
+		resigned, err := signer.Sign(http.MethodGet, oldLink, time.Minute)
+		assert.NoError(t, err)
+		u, _ := url.Parse(resigned)
+		assert.Len(t, u.Query()[SignatureParam], 1)
+		assert.NoError(t, signer.Verify(http.MethodGet, resigned))
+	})
+
+	t.Run("TC05: 无效配置", func(t *testing.T) {
+		_, err := NewURLSigner("missing", keys)
+		assert.Error(t, err)
+		_, err = NewURLSigner("k", map[string][]byte{"k": nil})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,250 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// SSRF 校验的错误，可用 errors.Is 判断
+var (
+	ErrBlockedScheme  = errors.New("scheme not allowed")
+	ErrBlockedPort    = errors.New("port not allowed")
+	ErrBlockedAddress = errors.New("address not allowed")
+)
+
+// DefaultBlockedPrefixes SSRFGuard 默认拒绝的网段：本机、私有网络、链路本地（含云厂商元数据地址 169.254.169.254）、
+// 运营商级 NAT（含 100.100.100.200）、保留、组播和 NAT64 地址
+var DefaultBlockedPrefixes = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+}
+
+// SSRFGuard 校验用户提供的 URL 和出站连接，防止服务端请求伪造：
+// ValidateURL 在请求前检查协议、端口和 DNS 解析结果，Control 在建立连接时再次检查实际连接的地址，防止 DNS 重绑定。可并发使用
+type SSRFGuard struct {
+	schemes  map[string]bool
+	ports    map[int]bool
+	blocked  *PrefixSet
+	allowed  *PrefixSet
+	resolver *net.Resolver
+}
+
+// SSRFOption SSRFGuard 的配置项
+type SSRFOption func(*ssrfConfig)
+
+type ssrfConfig struct {
+	schemes  []string
+	ports    []int
+	blocked  []string
+	allowed  []string
+	resolver *net.Resolver
+}
+
+// WithAllowedSchemes 设置允许的协议，默认 http、https
+func WithAllowedSchemes(schemes ...string) SSRFOption {
+	return func(cfg *ssrfConfig) {
+		cfg.schemes = schemes
+	}
+}
+
+// WithAllowedPorts 设置允许的端口，默认不限制
+func WithAllowedPorts(ports ...int) SSRFOption {
+	return func(cfg *ssrfConfig) {
+		cfg.ports = ports
+	}
+}
+
+// WithBlockedPrefixes 在 DefaultBlockedPrefixes 之外追加拒绝的网段（CIDR 或单个 IP）
+func WithBlockedPrefixes(prefixes ...string) SSRFOption {
+	return func(cfg *ssrfConfig) {
+		cfg.blocked = append(cfg.blocked, prefixes...)
+	}
+}
+
+// WithAllowedPrefixes 设置例外放行的网段，优先于拒绝列表，如允许回调内部的固定服务
+func WithAllowedPrefixes(prefixes ...string) SSRFOption {
+	return func(cfg *ssrfConfig) {
+		cfg.allowed = append(cfg.allowed, prefixes...)
+	}
+}
+
+// WithResolver 设置 ValidateURL 使用的 DNS 解析器，默认 net.DefaultResolver
+func WithResolver(r *net.Resolver) SSRFOption {
+	return func(cfg *ssrfConfig) {
+		cfg.resolver = r
+	}
+}
+
+// NewSSRFGuard 创建 SSRF 校验器
+func NewSSRFGuard(opts ...SSRFOption) (*SSRFGuard, error) {
+	cfg := &ssrfConfig{
+		schemes:  []string{"http", "https"},
+		blocked:  append([]string(nil), DefaultBlockedPrefixes...),
+		resolver: net.DefaultResolver,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	blocked, err := ParsePrefixSet(cfg.blocked)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := ParsePrefixSet(cfg.allowed)
+	if err != nil {
+		return nil, err
+	}
+	g := &SSRFGuard{
+		schemes:  make(map[string]bool, len(cfg.schemes)),
+		blocked:  blocked,
+		allowed:  allowed,
+		resolver: cfg.resolver,
+	}
+	for _, s := range cfg.schemes {
+		g.schemes[strings.ToLower(s)] = true
+	}
+	if len(cfg.ports) > 0 {
+		g.ports = make(map[int]bool, len(cfg.ports))
+		for _, p := range cfg.ports {
+			g.ports[p] = true
+		}
+	}
+	return g, nil
+}
+
+// CheckAddr 检查地址是否允许连接
+func (g *SSRFGuard) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	if !addr.IsValid() {
+		return fmt.Errorf("%w: 无效的地址", ErrBlockedAddress)
+	}
+	if g.allowed.Contains(addr) {
+		return nil
+	}
+	if g.blocked.Contains(addr) || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// checkPort 检查端口是否允许
+func (g *SSRFGuard) checkPort(port int) error {
+	if g.ports != nil && !g.ports[port] {
+		return fmt.Errorf("%w: %d", ErrBlockedPort, port)
+	}
+	return nil
+}
+
+// ValidateURL 检查 URL 的协议、端口，并解析主机名检查所有解析结果，任一地址被拒绝即返回错误
+func (g *SSRFGuard) ValidateURL(ctx context.Context, rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的URL: %w", err)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !g.schemes[scheme] {
+		return nil, fmt.Errorf("%w: %q", ErrBlockedScheme, u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return nil, errors.New("URL 缺少主机名")
+	}
+
+	portStr := u.Port()
+	if portStr == "" {
+		portStr = defaultPorts[scheme]
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrBlockedPort, portStr)
+	}
+	if err := g.checkPort(port); err != nil {
+		return nil, err
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if err := g.CheckAddr(addr); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+	addrs, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("解析主机 %s 失败: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if err := g.CheckAddr(addr); err != nil {
+			return nil, fmt.Errorf("%s: %w", host, err)
+		}
+	}
+	return u, nil
+}
+
+// Control 用作 net.Dialer 的 Control，在连接建立前检查实际连接的地址和端口
+func (g *SSRFGuard) Control(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrBlockedAddress, address)
+	}
+	if err := g.checkPort(int(ap.Port())); err != nil {
+		return err
+	}
+	return g.CheckAddr(ap.Addr())
+}
+
+// Dialer 返回使用 Control 检查连接地址的 net.Dialer
+func (g *SSRFGuard) Dialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   g.Control,
+	}
+}
+
+// Transport 返回连接时检查地址的 http.Transport，不使用环境变量中的代理（经代理连接时无法检查目标地址），
+// 可配合 WithHTTPClient 使用
+func (g *SSRFGuard) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = g.Dialer().DialContext
+	return t
+}
+
+// WithSSRFGuard 让 Client 通过 g.Transport() 发送请求，连接被拒绝的地址时返回 ErrBlockedAddress；
+// 与选项顺序无关，总是替换 WithHTTPClient 设置的 Transport，WithTransportMiddleware 添加的中间件包裹在其外层
+func WithSSRFGuard(g *SSRFGuard) ClientOption {
+	return func(c *Client) error {
+		c.ssrfGuard = g
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSRFGuard(t *testing.T) {
+	guard, err := NewSSRFGuard()
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("TC01: 地址检查", func(t *testing.T) {
+		blocked := []string{"127.0.0.1", "10.1.2.3", "172.20.0.1", "192.168.1.1", "169.254.169.254", "100.100.100.200",
+			"0.0.0.0", "::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1", "224.0.0.1"}
+		for _, s := range blocked {
+			assert.True(t, errors.Is(guard.CheckAddr(netip.MustParseAddr(s)), ErrBlockedAddress), s)
+		}
+		for _, s := range []string{"8.8.8.8", "2606:4700:4700::1111"} {
+			assert.NoError(t, guard.CheckAddr(netip.MustParseAddr(s)), s)
+		}
+	})
+
+	t.Run("TC02: URL 校验", func(t *testing.T) {
+		_, err := guard.ValidateURL(ctx, "https://8.8.8.8/hook")
+		assert.NoError(t, err)
+		_, err = guard.ValidateURL(ctx, "file:///etc/passwd")
+		assert.True(t, errors.Is(err, ErrBlockedScheme))
+		_, err = guard.ValidateURL(ctx, "gopher://8.8.8.8")
+		assert.True(t, errors.Is(err, ErrBlockedScheme))
+		_, err = guard.ValidateURL(ctx, "http://[::1]:8080/")
+		assert.True(t, errors.Is(err, ErrBlockedAddress))
+		_, err = guard.ValidateURL(ctx, "http://169.254.169.254/latest/meta-data/")
+		assert.True(t, errors.Is(err, ErrBlockedAddress))
+		_, err = guard.ValidateURL(ctx, "http://localhost/")
+		assert.True(t, errors.Is(err, ErrBlockedAddress))
+		_, err = guard.ValidateURL(ctx, "http:///path")
+		assert.Error(t, err)
+
+		strict, err := NewSSRFGuard(WithAllowedSchemes("https"), WithAllowedPorts(443), WithBlockedPrefixes("8.8.0.0/16"))
+		assert.NoError(t, err)
+		_, err = strict.ValidateURL(ctx, "https://1.1.1.1:8443/")
+		assert.True(t, errors.Is(err, ErrBlockedPort))
+		_, err = strict.ValidateURL(ctx, "https://8.8.8.8/")
+		assert.True(t, errors.Is(err, ErrBlockedAddress))
+		_, err = strict.ValidateURL(ctx, "https://1.1.1.1/")
+		assert.NoError(t, err)
+
+		_, err = NewSSRFGuard(WithBlockedPrefixes("bad"))
+		assert.Error(t, err)
+	})
+
+	t.Run("TC03: 连接时检查", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: guard.Transport()}
+		_, err := client.Get(server.URL)
+		assert.True(t, errors.Is(err, ErrBlockedAddress))
+
+		allowLoopback, err := NewSSRFGuard(WithAllowedPrefixes("127.0.0.1"))
+		assert.NoError(t, err)
+		client = &http.Client{Transport: allowLoopback.Transport()}
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// 与 WithHTTPClient 的先后顺序无关
+		for _, opts := range [][]ClientOption{
+			{WithSSRFGuard(guard)},
+			{WithSSRFGuard(guard), WithHTTPClient(&http.Client{})},
+			{WithHTTPClient(&http.Client{}), WithSSRFGuard(guard)},
+		} {
+			c, err := NewClient(append([]ClientOption{WithBaseURL(server.URL)}, opts...)...)
+			assert.NoError(t, err)
+			_, err = c.Do(ctx, &Request{Path: "/"})
+			assert.True(t, errors.Is(err, ErrBlockedAddress))
+		}
+	})
+}
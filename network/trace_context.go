@@ -0,0 +1,176 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context 请求头
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// maxTraceStateMembers tracestate 最多保留的条目数
+const maxTraceStateMembers = 32
+
+// ErrInvalidTraceParent traceparent 格式无效
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// TraceContext W3C Trace Context（https://www.w3.org/TR/trace-context/）中的链路信息
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte // traceparent 中的 parent-id，即当前调用方的 span
+	Flags   byte
+	State   string // tracestate 原始值
+}
+
+// NewTraceContext 生成新的链路（随机 trace-id 和 span-id），默认标记为采样
+func NewTraceContext() TraceContext {
+	var tc TraceContext
+	_, _ = rand.Read(tc.TraceID[:])
+	_, _ = rand.Read(tc.SpanID[:])
+	tc.Flags = 0x01
+	return tc
+}
+
+// ParseTraceParent 解析 traceparent 请求头，如 "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func ParseTraceParent(s string) (TraceContext, error) {
+	var tc TraceContext
+	s = strings.TrimSpace(s)
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tc, ErrInvalidTraceParent
+	}
+	version, err := hex.DecodeString(s[:2])
+	if err != nil || version[0] == 0xff || strings.ToLower(s) != s {
+		return tc, ErrInvalidTraceParent
+	}
+	// 版本 00 长度固定，更高版本允许在后面追加字段
+	if len(s) > 55 && (version[0] == 0 || s[55] != '-') {
+		return tc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(tc.TraceID[:], []byte(s[3:35])); err != nil {
+		return tc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(tc.SpanID[:], []byte(s[36:52])); err != nil {
+		return tc, ErrInvalidTraceParent
+	}
+	flags, err := hex.DecodeString(s[53:55])
+	if err != nil {
+		return tc, ErrInvalidTraceParent
+	}
+	tc.Flags = flags[0]
+	if !tc.IsValid() {
+		return TraceContext{}, ErrInvalidTraceParent
+	}
+	return tc, nil
+}
+
+// IsValid trace-id 和 span-id 都不为全零
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// Sampled 是否标记为采样
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&0x01 == 0x01
+}
+
+// TraceIDString 返回十六进制的 trace-id
+func (tc TraceContext) TraceIDString() string {
+	return hex.EncodeToString(tc.TraceID[:])
+}
+
+// SpanIDString 返回十六进制的 span-id
+func (tc TraceContext) SpanIDString() string {
+	return hex.EncodeToString(tc.SpanID[:])
+}
+
+// String 返回 traceparent 请求头的值
+func (tc TraceContext) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceIDString(), tc.SpanIDString(), tc.Flags)
+}
+
+// ChildSpan 在同一链路中生成新的 span，保留采样标记和 tracestate
+func (tc TraceContext) ChildSpan() TraceContext {
+	child := tc
+	_, _ = rand.Read(child.SpanID[:])
+	return child
+}
+
+// NormalizeTraceState 整理 tracestate：去掉空条目和格式错误（不含 "="）的条目，同名 key 只保留第一个，最多保留 32 条
+func NormalizeTraceState(s string) string {
+	var members []string
+	seen := make(map[string]bool)
+	for _, m := range strings.Split(s, ",") {
+		m = strings.TrimSpace(m)
+		key, _, ok := strings.Cut(m, "=")
+		if !ok || key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		members = append(members, m)
+		if len(members) == maxTraceStateMembers {
+			break
+		}
+	}
+	return strings.Join(members, ",")
+}
+
+// TraceContextFromHeader 从请求头解析链路信息
+func TraceContextFromHeader(h http.Header) (TraceContext, error) {
+	tc, err := ParseTraceParent(h.Get(TraceParentHeader))
+	if err != nil {
+		return tc, err
+	}
+	tc.State = NormalizeTraceState(strings.Join(h.Values(TraceStateHeader), ","))
+	return tc, nil
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext 将链路信息保存到 context
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext 从 context 获取链路信息
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// InjectTraceHeaders 将 context 中的请求ID 和链路信息写入出站请求头：traceparent 的 parent-id 为当前服务的 span；
+// context 中没有链路信息时生成新的链路；请求头中已有的值保持不变
+func InjectTraceHeaders(ctx context.Context, h http.Header) {
+	if id := RequestIDFromContext(ctx); id != "" && h.Get(RequestIDHeader) == "" {
+		h.Set(RequestIDHeader, id)
+	}
+	if h.Get(TraceParentHeader) != "" {
+		return
+	}
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok || !tc.IsValid() {
+		tc = NewTraceContext()
+	}
+	h.Set(TraceParentHeader, tc.String())
+	if tc.State != "" {
+		h.Set(TraceStateHeader, tc.State)
+	}
+}
+
+// TraceContextTransport 使用 InjectTraceHeaders 为出站请求传递请求ID 和链路信息
+func TraceContextTransport() TransportMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			InjectTraceHeaders(req.Context(), req.Header)
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceContext(t *testing.T) {
+	t.Run("TC01: 解析 traceparent", func(t *testing.T) {
+		tc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		assert.NoError(t, err)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tc.TraceIDString())
+		assert.Equal(t, "00f067aa0ba902b7", tc.SpanIDString())
+		assert.True(t, tc.Sampled())
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", tc.String())
+
+		// 更高版本允许追加字段
+		_, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+		assert.NoError(t, err)
+
+		invalid := []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+		}
+		for _, s := range invalid {
+			_, err := ParseTraceParent(s)
+			assert.ErrorIs(t, err, ErrInvalidTraceParent, s)
+		}
+	})
+
+	t.Run("TC02: 新链路与子 span", func(t *testing.T) {
+		tc := NewTraceContext()
+		assert.True(t, tc.IsValid())
+		child := tc.ChildSpan()
+		assert.Equal(t, tc.TraceID, child.TraceID)
+		assert.NotEqual(t, tc.SpanID, child.SpanID)
+		assert.NotEqual(t, tc.TraceID, NewTraceContext().TraceID)
+
+		assert.Equal(t, "a=1,b=2", NormalizeTraceState(" a=1 ,, bad, b=2, a=3"))
+	})
+
+	t.Run("TC03: 出站传递", func(t *testing.T) {
+		var got http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+		}))
+		defer server.Close()
+
+		tc, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		tc.State = "vendor=x"
+		ctx := ContextWithTraceContext(ContextWithRequestID(context.Background(), "req-9"), tc)
+
+		client, err := NewClient(WithBaseURL(server.URL), WithTransportMiddleware(TraceContextTransport()))
+		assert.NoError(t, err)
+		resp, err := client.Do(ctx, &Request{Path: "/"})
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "req-9", got.Get(RequestIDHeader))
+		assert.Equal(t, tc.String(), got.Get(TraceParentHeader))
+		assert.Equal(t, "vendor=x", got.Get(TraceStateHeader))
+
+		h := make(http.Header)
+		InjectTraceHeaders(context.Background(), h)
+		parsed, err := TraceContextFromHeader(h)
+		assert.NoError(t, err)
+		assert.True(t, parsed.IsValid())
+		assert.Empty(t, h.Get(RequestIDHeader))
+	})
+}
@@ -0,0 +1,317 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+
+	loop "github.com/hargeek/gopkg/loop"
+	"github.com/hargeek/gopkg/ratelimit"
+)
+
+// ErrResponseTooLarge 响应体超过 MaxResponseSizeTransport 的限制
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// RoundTripperFunc 将普通函数适配为 http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TransportMiddleware http.RoundTripper 中间件
+type TransportMiddleware func(next http.RoundTripper) http.RoundTripper
+
+// ChainTransport 按顺序组装中间件，第一个中间件位于最外层；base 为 nil 时使用 http.DefaultTransport
+func ChainTransport(base http.RoundTripper, middlewares ...TransportMiddleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+// WithTransportMiddleware 为 Client 的 Transport 添加中间件（在所有配置项应用后组装，不修改传入的 http.Client）
+func WithTransportMiddleware(middlewares ...TransportMiddleware) ClientOption {
+	return func(c *Client) error {
+		c.middlewares = append(c.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// DefaultRedactedHeaders LoggingTransport 默认脱敏的请求头
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// LoggingTransport 记录每个请求的方法、地址、状态码、耗时和请求头，redactHeaders 中的请求头值替换为 "REDACTED"，
+// 为空时使用 DefaultRedactedHeaders；logger 为 nil 时使用 slog.Default()
+func LoggingTransport(logger *slog.Logger, redactHeaders ...string) TransportMiddleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if len(redactHeaders) == 0 {
+		redactHeaders = DefaultRedactedHeaders
+	}
+	redact := make(map[string]bool, len(redactHeaders))
+	for _, h := range redactHeaders {
+		redact[http.CanonicalHeaderKey(h)] = true
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if id := req.Header.Get(RequestIDHeader); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			headers := make([]any, 0, len(req.Header))
+			for k, vs := range req.Header {
+				v := strings.Join(vs, ", ")
+				if redact[k] {
+					v = "REDACTED"
+				}
+				headers = append(headers, slog.String(k, v))
+			}
+			attrs = append(attrs, slog.Group("headers", headers...))
+
+			level := slog.LevelInfo
+			if err != nil {
+				level = slog.LevelError
+				attrs = append(attrs, slog.Any("error", err))
+			} else {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			}
+			logger.LogAttrs(req.Context(), level, "http request", attrs...)
+			return resp, err
+		})
+	}
+}
+
+// RequestIDTransport 为请求设置请求ID头：优先使用 context 中的请求ID（ContextWithRequestID），否则生成新的；
+// 请求已带有该头时保持不变；header 为空时使用 RequestIDHeader
+func RequestIDTransport(header string) TransportMiddleware {
+	if header == "" {
+		header = RequestIDHeader
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) != "" {
+				return next.RoundTrip(req)
+			}
+			id := RequestIDFromContext(req.Context())
+			if id == "" {
+				id = NewRequestID()
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(header, id)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RateLimitTransport 按目标主机限流，每个主机使用独立的令牌桶（每秒 rate 个，容量 burst），等待令牌时遵循请求的 context
+func RateLimitTransport(rate float64, burst int, opts ...ratelimit.Option) TransportMiddleware {
+	var mu sync.Mutex
+	buckets := make(map[string]*ratelimit.TokenBucket)
+	bucket := func(host string) *ratelimit.TokenBucket {
+		mu.Lock()
+		defer mu.Unlock()
+		b, ok := buckets[host]
+		if !ok {
+			b = ratelimit.NewTokenBucket(rate, burst, opts...)
+			buckets[host] = b
+		}
+		return b
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := bucket(req.URL.Host).Wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RetryTransport 对幂等请求（幂等方法或带 Idempotency-Key 头）在网络错误和 429/5xx 响应时重试，响应带 Retry-After 时按其等待，
+// 超过 DefaultMaxRetryAfter（或 loop.WithMaxElapsedTime 剩余时间）时不再重试；
+// 重试耗尽后返回最后一次的响应（而不是错误），请求体需要可重放（req.GetBody），否则不重试
+func RetryTransport(opts ...loop.Option) TransportMiddleware {
+	opts = append([]loop.Option{loop.WithShouldRetry(shouldRetryRequest)}, opts...)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+			idempotent := isIdempotent(req.Method) || req.Header.Get("Idempotency-Key") != ""
+			if !replayable || !idempotent {
+				return next.RoundTrip(req)
+			}
+
+			var last *http.Response
+			resp, err := loop.RetryValue(req.Context(), func(ctx context.Context) (*http.Response, error) {
+				if last != nil {
+					drainAndClose(last.Body)
+					last = nil
+				}
+				attempt := req.Clone(ctx)
+				if req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, loop.Permanent(err)
+					}
+					attempt.Body = body
+				}
+				resp, err := next.RoundTrip(attempt)
+				if err != nil {
+					return nil, err
+				}
+				if !loop.IsRetryableStatusCode(resp.StatusCode) {
+					return resp, nil
+				}
+				last = resp
+				return nil, withRetryAfter(loop.StatusError(resp.StatusCode, nil), resp.StatusCode, resp.Header, DefaultMaxRetryAfter)
+			}, opts...)
+			if err != nil && last != nil && req.Context().Err() == nil {
+				return last, nil
+			}
+			if err != nil && last != nil {
+				drainAndClose(last.Body)
+			}
+			return resp, err
+		})
+	}
+}
+
+// CircuitBreakerTransport 按目标主机熔断，网络错误和 5xx 响应计为失败，熔断时返回 loop.ErrCircuitOpen；
+// settings.Name 为空时使用主机名
+func CircuitBreakerTransport(settings loop.BreakerSettings) TransportMiddleware {
+	var mu sync.Mutex
+	breakers := make(map[string]*loop.CircuitBreaker)
+	breaker := func(host string) *loop.CircuitBreaker {
+		mu.Lock()
+		defer mu.Unlock()
+		cb, ok := breakers[host]
+		if !ok {
+			s := settings
+			if s.Name == "" {
+				s.Name = host
+			}
+			cb = loop.NewCircuitBreaker(s)
+			breakers[host] = cb
+		}
+		return cb
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			done, err := breaker(req.URL.Host).Allow()
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, err)
+			}
+			resp, err := next.RoundTrip(req)
+			switch {
+			case err != nil:
+				done(err)
+			case resp.StatusCode >= http.StatusInternalServerError:
+				done(loop.StatusError(resp.StatusCode, nil))
+			default:
+				done(nil)
+			}
+			return resp, err
+		})
+	}
+}
+
+// MaxResponseSizeTransport 限制响应体大小：Content-Length 超过 limit 时直接返回错误，否则读取超过 limit 字节时返回 ErrResponseTooLarge
+func MaxResponseSizeTransport(limit int64) TransportMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return resp, err
+			}
+			if resp.ContentLength > limit {
+				drainAndClose(resp.Body)
+				return nil, fmt.Errorf("%s %s: %w (%d > %d)", req.Method, req.URL.Redacted(), ErrResponseTooLarge, resp.ContentLength, limit)
+			}
+			resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: limit}
+			return resp, nil
+		})
+	}
+}
+
+// DumpTransport 将完整的请求和响应（含请求体、响应体）写入 w，用于调试，不要在生产环境使用
+func DumpTransport(w io.Writer) TransportMiddleware {
+	var mu sync.Mutex
+	write := func(b []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(b)
+		_, _ = w.Write([]byte("\n"))
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if b, err := httputil.DumpRequestOut(req, true); err == nil {
+				write(b)
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				write([]byte(fmt.Sprintf("%s %s: %v", req.Method, req.URL.Redacted(), err)))
+				return resp, err
+			}
+			if b, err := httputil.DumpResponse(resp, true); err == nil {
+				write(b)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// limitedBody 读取超过限制时返回 ErrResponseTooLarge
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// 探测是否还有数据，确认超限而不是恰好读完
+		var one [1]byte
+		n, err := b.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// drainAndClose 丢弃剩余响应体并关闭，便于连接复用
+func drainAndClose(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 4<<10))
+	_ = body.Close()
+}
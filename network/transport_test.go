@@ -0,0 +1,177 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loop "github.com/hargeek/gopkg/loop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportMiddleware(t *testing.T) {
+	var flaky, broken atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			w.Header().Set("X-Got-Request-ID", r.Header.Get(RequestIDHeader))
+			_, _ = w.Write([]byte("ok"))
+		case "/flaky":
+			if flaky.Add(1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		case "/broken":
+			broken.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("broken"))
+		case "/busy":
+			broken.Add(1)
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+		}
+	}))
+	defer server.Close()
+
+	t.Run("TC01: 中间件顺序", func(t *testing.T) {
+		var order []string
+		mark := func(name string) TransportMiddleware {
+			return func(next http.RoundTripper) http.RoundTripper {
+				return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					order = append(order, name)
+					return next.RoundTrip(req)
+				})
+			}
+		}
+		client, err := NewClient(WithBaseURL(server.URL), WithTransportMiddleware(mark("a"), mark("b")), WithTransportMiddleware(mark("c")))
+		assert.NoError(t, err)
+		resp, err := client.Do(context.Background(), &Request{Path: "/echo"})
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, []string{"a", "b", "c"}, order)
+	})
+
+	t.Run("TC02: 日志脱敏与请求ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		client, err := NewClient(
+			WithBaseURL(server.URL),
+			WithHeader("Authorization", "Bearer secret"),
+			WithTransportMiddleware(RequestIDTransport(""), LoggingTransport(logger)),
+		)
+		assert.NoError(t, err)
+
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		resp, err := client.Do(ctx, &Request{Path: "/echo"})
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "req-1", resp.Header.Get("X-Got-Request-ID"))
+		assert.Contains(t, buf.String(), `"request_id":"req-1"`)
+		assert.Contains(t, buf.String(), `"Authorization":"REDACTED"`)
+		assert.NotContains(t, buf.String(), "secret")
+
+		resp, err = client.Do(context.Background(), &Request{Path: "/echo"})
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Len(t, resp.Header.Get("X-Got-Request-ID"), 32)
+	})
+
+	t.Run("TC03: 重试", func(t *testing.T) {
+		rt := ChainTransport(nil, RetryTransport(loop.WithBackoff(loop.ConstantBackoff(time.Millisecond))))
+		hc := &http.Client{Transport: rt}
+
+		resp, err := hc.Get(server.URL + "/flaky")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), flaky.Load())
+
+		// 重试耗尽后返回最后一次的响应
+		resp, err = hc.Get(server.URL + "/broken")
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, "broken", string(body))
+		assert.Equal(t, int32(loop.DefaultMaxAttempts), broken.Load())
+
+		// Retry-After 超过上限时直接返回该响应
+		broken.Store(0)
+		start := time.Now()
+		resp, err = hc.Get(server.URL + "/busy")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, int32(1), broken.Load())
+		assert.Less(t, time.Since(start), time.Second)
+
+		// 非幂等请求不重试
+		broken.Store(0)
+		resp, err = hc.Post(server.URL+"/broken", "text/plain", strings.NewReader("x"))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, int32(1), broken.Load())
+	})
+
+	t.Run("TC04: 熔断", func(t *testing.T) {
+		broken.Store(0)
+		rt := ChainTransport(nil, CircuitBreakerTransport(loop.BreakerSettings{ConsecutiveFailures: 2, CoolDown: time.Minute}))
+		hc := &http.Client{Transport: rt}
+		for i := 0; i < 2; i++ {
+			resp, err := hc.Get(server.URL + "/broken")
+			assert.NoError(t, err)
+			resp.Body.Close()
+		}
+		_, err := hc.Get(server.URL + "/broken")
+		assert.True(t, errors.Is(err, loop.ErrCircuitOpen))
+		assert.Equal(t, int32(2), broken.Load())
+	})
+
+	t.Run("TC05: 响应体大小限制", func(t *testing.T) {
+		hc := &http.Client{Transport: ChainTransport(nil, MaxResponseSizeTransport(10))}
+		_, err := hc.Get(server.URL + "/large")
+		assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+		hc = &http.Client{Transport: ChainTransport(nil, MaxResponseSizeTransport(100))}
+		resp, err := hc.Get(server.URL + "/large")
+		assert.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Len(t, body, 100)
+
+		// 未知长度时读取超限返回错误
+		rt := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, ContentLength: -1, Body: io.NopCloser(strings.NewReader(strings.Repeat("y", 20)))}, nil
+		})
+		hc = &http.Client{Transport: ChainTransport(rt, MaxResponseSizeTransport(10))}
+		resp, err = hc.Get(server.URL + "/large")
+		assert.NoError(t, err)
+		_, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.True(t, errors.Is(err, ErrResponseTooLarge))
+	})
+
+	t.Run("TC06: 调试输出", func(t *testing.T) {
+		var buf bytes.Buffer
+		hc := &http.Client{Transport: ChainTransport(nil, DumpTransport(&buf))}
+		resp, err := hc.Get(server.URL + "/echo")
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "ok", string(body))
+		assert.Contains(t, buf.String(), "GET /echo HTTP/1.1")
+		assert.Contains(t, buf.String(), "200 OK")
+	})
+}
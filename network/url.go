@@ -22,6 +22,7 @@ func GetBasePathFromURL(URL string) (string, error) {
 
 // NormalizePath 将带参数的路径转换为通配符格式
 // 例如：/api/v1/user/123 -> /api/v1/user/*
+// 只替换最后一段的十进制整数，需要替换任意段或其他 ID 格式时使用 PathNormalizer
 func NormalizePath(path string) string {
 	// 处理空路径和根路径
 	if path == "" || path == "/" {
@@ -0,0 +1,368 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URLBuilder 链式构建和修改 URL，过程中的错误会被记录，由 Build 或 Err 返回，出错后的操作不再生效；不可并发使用
+type URLBuilder struct {
+	u   *url.URL
+	err error
+}
+
+// NewURLBuilder 解析 rawURL 创建构建器，rawURL 可以是相对地址
+func NewURLBuilder(rawURL string) *URLBuilder {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &URLBuilder{u: &url.URL{}, err: fmt.Errorf("无效的URL: %w", err)}
+	}
+	return &URLBuilder{u: u}
+}
+
+// URLBuilderFrom 基于已有的 url.URL 创建构建器，不会修改 u
+func URLBuilderFrom(u *url.URL) *URLBuilder {
+	copied := *u
+	if u.User != nil {
+		user := *u.User
+		copied.User = &user
+	}
+	return &URLBuilder{u: &copied}
+}
+
+// JoinPath 追加路径段，每个参数作为一个完整的段转义（其中的 "/" 会被编码为 %2F），空段被忽略
+func (b *URLBuilder) JoinPath(segments ...string) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	escaped := strings.TrimSuffix(b.u.EscapedPath(), "/")
+	for _, s := range segments {
+		if s == "" {
+			continue
+		}
+		escaped += "/" + url.PathEscape(s)
+	}
+	if escaped == "" {
+		escaped = "/"
+	}
+	b.setEscapedPath(escaped)
+	return b
+}
+
+// AddQuery 追加查询参数，保留同名的已有值
+func (b *URLBuilder) AddQuery(key string, values ...string) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	q := b.u.Query()
+	for _, v := range values {
+		q.Add(key, v)
+	}
+	b.u.RawQuery = q.Encode()
+	return b
+}
+
+// SetQuery 设置查询参数，替换同名的已有值
+func (b *URLBuilder) SetQuery(key string, values ...string) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	q := b.u.Query()
+	q[key] = append([]string(nil), values...)
+	b.u.RawQuery = q.Encode()
+	return b
+}
+
+// DelQuery 删除查询参数
+func (b *URLBuilder) DelQuery(keys ...string) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	q := b.u.Query()
+	for _, k := range keys {
+		q.Del(k)
+	}
+	b.u.RawQuery = q.Encode()
+	return b
+}
+
+// MergeQuery 合并查询参数，params 为 url.Values 或可被 ConvertToQueryParams 转换的结构体，同名参数会被替换
+func (b *URLBuilder) MergeQuery(params interface{}, opts ...QueryOption) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	var extra url.Values
+	switch p := params.(type) {
+	case url.Values:
+		extra = p
+	default:
+		var err error
+		if extra, err = ConvertToQueryParams(params, opts...); err != nil {
+			b.err = err
+			return b
+		}
+	}
+	q := b.u.Query()
+	for k, vs := range extra {
+		q[k] = append([]string(nil), vs...)
+	}
+	b.u.RawQuery = q.Encode()
+	return b
+}
+
+// Fragment 设置片段（# 之后的部分），为空时移除
+func (b *URLBuilder) Fragment(fragment string) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.u.Fragment = fragment
+	b.u.RawFragment = ""
+	return b
+}
+
+// Resolve 按 RFC 3986 以当前地址为基准解析相对引用 ref，如 "../b?x=1"
+func (b *URLBuilder) Resolve(ref string) *URLBuilder {
+	if b.err != nil {
+		return b
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		b.err = fmt.Errorf("无效的相对地址: %w", err)
+		return b
+	}
+	b.u = b.u.ResolveReference(r)
+	return b
+}
+
+// StripCredentials 移除 URL 中的用户名和密码
+func (b *URLBuilder) StripCredentials() *URLBuilder {
+	if b.err == nil {
+		b.u.User = nil
+	}
+	return b
+}
+
+// Segments 返回解码后的路径段，忽略空段，如 "/a/b%2Fc/" -> ["a", "b/c"]
+func (b *URLBuilder) Segments() []string {
+	return PathSegments(b.u)
+}
+
+// Segment 返回第 i 个路径段，i 为负数时从末尾计数（-1 为最后一段），越界时 ok 为 false
+func (b *URLBuilder) Segment(i int) (string, bool) {
+	segments := b.Segments()
+	if i < 0 {
+		i += len(segments)
+	}
+	if i < 0 || i >= len(segments) {
+		return "", false
+	}
+	return segments[i], true
+}
+
+// Err 返回构建过程中的第一个错误
+func (b *URLBuilder) Err() error {
+	return b.err
+}
+
+// Build 返回构建结果的副本
+func (b *URLBuilder) Build() (*url.URL, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	copied := *b.u
+	return &copied, nil
+}
+
+// String 返回构建结果，出错时返回空字符串
+func (b *URLBuilder) String() string {
+	if b.err != nil {
+		return ""
+	}
+	return b.u.String()
+}
+
+// setEscapedPath 设置已转义的路径
+func (b *URLBuilder) setEscapedPath(escaped string) {
+	p, err := url.PathUnescape(escaped)
+	if err != nil {
+		b.err = fmt.Errorf("无效的路径: %w", err)
+		return
+	}
+	b.u.Path = p
+	b.u.RawPath = escaped
+}
+
+// PathSegments 返回 u 解码后的路径段，忽略空段；与 GetBasePathFromURL 不同，末尾斜杠不会产生空段
+func PathSegments(u *url.URL) []string {
+	var segments []string
+	for _, s := range strings.Split(u.EscapedPath(), "/") {
+		if s == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			s = unescaped
+		}
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+// LastPathSegment 返回最后一个非空路径段，如 "https://a.com/x/y/" -> "y"，路径为空时返回空字符串
+func LastPathSegment(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	segments := PathSegments(u)
+	if len(segments) == 0 {
+		return "", nil
+	}
+	return segments[len(segments)-1], nil
+}
+
+// defaultPorts 各协议的默认端口
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// CanonicalizeURL 规范化 URL：协议和主机名转小写，移除默认端口和空的 "?"、"#"，解码路径中编码过的非保留字符（如 %7E -> ~）
+// 并将其余百分号编码转为大写（RFC 3986 6.2.2），移除路径中的 "." 和 ".." 段，
+// http(s) 的空路径补为 "/"，查询参数按 key 排序（同名参数保持原有顺序）
+func CanonicalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("无效的URL: %w", err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	if u.Host != "" {
+		host, port := u.Hostname(), u.Port()
+		host = strings.ToLower(host)
+		if port == defaultPorts[u.Scheme] {
+			port = ""
+		}
+		switch {
+		case port != "":
+			host = net.JoinHostPort(host, port)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+		u.Host = host
+	}
+
+	escaped := removeDotSegments(normalizePercentEncoding(u.EscapedPath()))
+	if escaped == "" && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https") {
+		escaped = "/"
+	}
+	b := &URLBuilder{u: u}
+	b.setEscapedPath(escaped)
+	if b.err != nil {
+		return "", b.err
+	}
+
+	if u.RawQuery != "" {
+		q, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return "", fmt.Errorf("无效的查询参数: %w", err)
+		}
+		u.RawQuery = q.Encode()
+	}
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// EqualURLs 判断两个 URL 规范化后是否相同，任一无法解析时返回 false
+func EqualURLs(a, b string) bool {
+	ca, err := CanonicalizeURL(a)
+	if err != nil {
+		return false
+	}
+	cb, err := CanonicalizeURL(b)
+	if err != nil {
+		return false
+	}
+	return ca == cb
+}
+
+// normalizePercentEncoding 按 RFC 3986 6.2.2.2 解码非保留字符（字母、数字、"-"、"."、"_"、"~"）的百分号编码，
+// 其余编码的十六进制数字转为大写，无效的编码保持原样
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteByte('%')
+			sb.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return sb.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	default:
+		return c - 'a' + 10
+	}
+}
+
+// isUnreserved 判断是否为 RFC 3986 中的非保留字符
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// removeDotSegments 按 RFC 3986 5.2.4 移除路径中的 "." 和 ".." 段
+func removeDotSegments(p string) string {
+	if !strings.Contains(p, ".") {
+		return p
+	}
+	var out []string
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		last := i == len(segments)-1
+		switch s {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 || (len(out) == 1 && out[0] != "") {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, s)
+		}
+	}
+	result := strings.Join(out, "/")
+	if strings.HasPrefix(p, "/") && !strings.HasPrefix(result, "/") {
+		result = "/" + result
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import "time"
+
+// Clock 时钟抽象，便于测试时注入可控的时间
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock 使用系统时间的时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Option 限流器配置项
+type Option func(*options)
+
+type options struct {
+	clock       Clock
+	idleTimeout time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{clock: realClock{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.clock == nil {
+		o.clock = realClock{}
+	}
+	return o
+}
+
+// WithClock 注入时钟，默认使用系统时间
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithIdleTimeout 设置按 key 限流时空闲 key 的清理间隔，默认为窗口长度
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = d
+	}
+}
@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock 可手动推进的时钟，After 会直接推进时间并立即返回
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("TC01: 突发容量与补充", func(t *testing.T) {
+		clock := newFakeClock()
+		b := NewTokenBucket(10, 3, WithClock(clock))
+		assert.True(t, b.Allow())
+		assert.True(t, b.Allow())
+		assert.True(t, b.Allow())
+		assert.False(t, b.Allow())
+
+		clock.Advance(100 * time.Millisecond)
+		assert.True(t, b.Allow())
+		assert.False(t, b.Allow())
+
+		clock.Advance(time.Hour)
+		assert.InDelta(t, 3, b.Tokens(), 1e-9)
+	})
+
+	t.Run("TC02: 预约与取消", func(t *testing.T) {
+		clock := newFakeClock()
+		b := NewTokenBucket(2, 1, WithClock(clock))
+		assert.True(t, b.Allow())
+
+		r := b.Reserve()
+		assert.True(t, r.OK())
+		assert.Equal(t, 500*time.Millisecond, r.Delay())
+		r.Cancel()
+		assert.InDelta(t, 0, b.Tokens(), 1e-9)
+
+		assert.False(t, b.ReserveN(2).OK())
+	})
+
+	t.Run("TC03: Wait", func(t *testing.T) {
+		clock := newFakeClock()
+		b := NewTokenBucket(1, 1, WithClock(clock))
+		start := clock.Now()
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.Equal(t, time.Second, clock.Now().Sub(start))
+
+		assert.ErrorIs(t, b.WaitN(context.Background(), 2), ErrExceedsBurst)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.Error(t, b.Wait(ctx))
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	t.Run("TC01: 按key限流", func(t *testing.T) {
+		clock := newFakeClock()
+		l := NewSlidingWindow(2, time.Minute, WithClock(clock))
+
+		res := l.Take("a")
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 1, res.Remaining)
+		clock.Advance(10 * time.Second)
+		assert.True(t, l.Allow("a"))
+		assert.True(t, l.Allow("b"))
+
+		res = l.Take("a")
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+		assert.Equal(t, 50*time.Second, res.RetryAfter)
+
+		clock.Advance(50 * time.Second)
+		assert.True(t, l.Allow("a"))
+		assert.False(t, l.Allow("a"))
+	})
+
+	t.Run("TC02: 清理空闲key", func(t *testing.T) {
+		clock := newFakeClock()
+		l := NewSlidingWindow(1, time.Second, WithClock(clock), WithIdleTimeout(time.Second))
+		for _, key := range []string{"a", "b", "c"} {
+			l.Allow(key)
+		}
+		assert.Equal(t, 3, l.Len())
+		clock.Advance(2 * time.Second)
+		l.Allow("d")
+		assert.Equal(t, 1, l.Len())
+	})
+
+	t.Run("TC03: 并发安全", func(t *testing.T) {
+		l := NewSlidingWindow(100, time.Minute)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 200; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if l.Allow("k") {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 100, allowed)
+	})
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Result 一次按 key 限流判断的结果，可直接用于填充 X-RateLimit-* 响应头
+type Result struct {
+	Allowed    bool          // 是否放行
+	Limit      int           // 窗口内允许的请求数
+	Remaining  int           // 窗口内剩余的请求数
+	RetryAfter time.Duration // 被拒绝时距离可再次请求的时间，放行时为0
+	ResetAfter time.Duration // 距离窗口内最早一次请求过期（释放一个名额）的时间
+}
+
+// SlidingWindow 按 key 的滑动窗口日志限流器：任意长度为 window 的时间段内每个 key 最多放行 limit 次
+// 空闲的 key 会在后续调用中被自动清理，可并发使用
+type SlidingWindow struct {
+	limit       int
+	window      time.Duration
+	clock       Clock
+	idleTimeout time.Duration
+
+	mu        sync.Mutex
+	logs      map[string][]time.Time
+	lastSweep time.Time
+}
+
+// NewSlidingWindow 创建滑动窗口限流器
+func NewSlidingWindow(limit int, window time.Duration, opts ...Option) *SlidingWindow {
+	o := newOptions(opts)
+	if o.idleTimeout <= 0 {
+		o.idleTimeout = window
+	}
+	return &SlidingWindow{
+		limit:       limit,
+		window:      window,
+		clock:       o.clock,
+		idleTimeout: o.idleTimeout,
+		logs:        make(map[string][]time.Time),
+		lastSweep:   o.clock.Now(),
+	}
+}
+
+// Limit 返回窗口内允许的请求数
+func (l *SlidingWindow) Limit() int { return l.limit }
+
+// Window 返回窗口长度
+func (l *SlidingWindow) Window() time.Duration { return l.window }
+
+// Allow 判断 key 是否放行，放行时计入一次请求
+func (l *SlidingWindow) Allow(key string) bool {
+	return l.Take(key).Allowed
+}
+
+// Take 判断 key 是否放行并返回详细结果，放行时计入一次请求
+func (l *SlidingWindow) Take(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	log := l.trim(l.logs[key], now)
+	res := Result{Limit: l.limit}
+	if len(log) < l.limit {
+		log = append(log, now)
+		res.Allowed = true
+	} else if len(log) > 0 {
+		res.RetryAfter = log[0].Add(l.window).Sub(now)
+	}
+	res.Remaining = l.limit - len(log)
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	if len(log) > 0 {
+		res.ResetAfter = log[0].Add(l.window).Sub(now)
+	}
+
+	if len(log) == 0 {
+		delete(l.logs, key)
+	} else {
+		l.logs[key] = log
+	}
+	return res
+}
+
+// Reset 清除 key 的请求记录
+func (l *SlidingWindow) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.logs, key)
+}
+
+// Len 返回当前跟踪的 key 数量
+func (l *SlidingWindow) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.logs)
+}
+
+// trim 移除窗口外的请求记录
+func (l *SlidingWindow) trim(log []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(log) && !log[i].After(cutoff) {
+		i++
+	}
+	if i == 0 {
+		return log
+	}
+	// 拷贝到新切片，避免底层数组随着时间无限增长
+	return append([]time.Time(nil), log[i:]...)
+}
+
+// sweep 每隔 idleTimeout 清理一次所有请求记录都已过期的 key
+func (l *SlidingWindow) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTimeout {
+		return
+	}
+	l.lastSweep = now
+	cutoff := now.Add(-l.window)
+	for key, log := range l.logs {
+		if len(log) == 0 || !log[len(log)-1].After(cutoff) {
+			delete(l.logs, key)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrExceedsBurst 请求的令牌数超过桶容量，永远无法满足
+var ErrExceedsBurst = errors.New("ratelimit: 请求的令牌数超过桶容量")
+
+// TokenBucket 令牌桶限流器，以固定速率补充令牌，最多容纳 burst 个，可并发使用
+type TokenBucket struct {
+	rate  float64 // 每秒补充的令牌数
+	burst int
+	clock Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket 创建令牌桶，rate 为每秒补充的令牌数（math.Inf(1) 表示不限流），burst 为桶容量，初始为满桶
+func NewTokenBucket(rate float64, burst int, opts ...Option) *TokenBucket {
+	o := newOptions(opts)
+	return &TokenBucket{
+		rate:   rate,
+		burst:  burst,
+		clock:  o.clock,
+		tokens: float64(burst),
+		last:   o.clock.Now(),
+	}
+}
+
+// Rate 返回每秒补充的令牌数
+func (b *TokenBucket) Rate() float64 { return b.rate }
+
+// Burst 返回桶容量
+func (b *TokenBucket) Burst() int { return b.burst }
+
+// Tokens 返回当前可用令牌数，存在未兑现的预约时可能为负
+func (b *TokenBucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.clock.Now())
+	return b.tokens
+}
+
+// Allow 立即获取 1 个令牌，获取不到返回 false
+func (b *TokenBucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+// AllowN 立即获取 n 个令牌，获取不到返回 false 且不消耗令牌
+func (b *TokenBucket) AllowN(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if math.IsInf(b.rate, 1) {
+		return true
+	}
+	b.advance(b.clock.Now())
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// Reserve 预约 1 个令牌，见 ReserveN
+func (b *TokenBucket) Reserve() *Reservation {
+	return b.ReserveN(1)
+}
+
+// ReserveN 预约 n 个令牌并立即扣除，调用方需等待 Reservation.Delay() 后再执行操作；
+// 不再执行时应调用 Cancel 归还令牌。n 超过桶容量或速率为0且令牌不足时返回的预约 OK() 为 false
+func (b *TokenBucket) ReserveN(n int) *Reservation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	r := &Reservation{bucket: b, tokens: n, timeToAct: now}
+	if math.IsInf(b.rate, 1) {
+		r.ok = true
+		return r
+	}
+	if n > b.burst {
+		return r
+	}
+	b.advance(now)
+	remaining := b.tokens - float64(n)
+	if remaining < 0 {
+		if b.rate <= 0 {
+			return r
+		}
+		r.timeToAct = now.Add(durationFromTokens(-remaining, b.rate))
+	}
+	b.tokens = remaining
+	r.ok = true
+	return r
+}
+
+// Wait 阻塞直到获取 1 个令牌或 ctx 结束
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return b.WaitN(ctx, 1)
+}
+
+// WaitN 阻塞直到获取 n 个令牌或 ctx 结束；若 ctx 的截止时间早于可获取的时间则立即返回错误
+func (b *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r := b.ReserveN(n)
+	if !r.OK() {
+		if n > b.burst {
+			return ErrExceedsBurst
+		}
+		return fmt.Errorf("ratelimit: 速率为0，无法获取 %d 个令牌", n)
+	}
+	delay := r.Delay()
+	if delay <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		r.Cancel()
+		return fmt.Errorf("ratelimit: 等待 %s 将超出 context 截止时间", delay)
+	}
+	select {
+	case <-b.clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// advance 按流逝的时间补充令牌
+func (b *TokenBucket) advance(now time.Time) {
+	if now.After(b.last) {
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// durationFromTokens 计算补充 tokens 个令牌所需的时间
+func durationFromTokens(tokens, rate float64) time.Duration {
+	seconds := tokens / rate
+	if seconds >= math.MaxInt64/float64(time.Second) {
+		return math.MaxInt64
+	}
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// Reservation 令牌预约
+type Reservation struct {
+	bucket    *TokenBucket
+	ok        bool
+	tokens    int
+	timeToAct time.Time
+	canceled  bool
+}
+
+// OK 预约是否成功
+func (r *Reservation) OK() bool { return r.ok }
+
+// Delay 距离可以执行操作还需等待的时间，预约失败时返回一个极大值
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return math.MaxInt64
+	}
+	d := r.timeToAct.Sub(r.bucket.clock.Now())
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Cancel 放弃预约，在执行时间到达前取消会归还令牌
+func (r *Reservation) Cancel() {
+	if !r.ok || math.IsInf(r.bucket.rate, 1) {
+		return
+	}
+	b := r.bucket
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.canceled {
+		return
+	}
+	r.canceled = true
+	now := b.clock.Now()
+	if !now.Before(r.timeToAct) {
+		return
+	}
+	b.advance(now)
+	b.tokens = math.Min(float64(b.burst), b.tokens+float64(r.tokens))
+}
@@ -7,22 +7,31 @@ import (
 	"time"
 )
 
-// ParseDuration 解析时间字符串
+// ParseDuration 解析时间字符串，在 time.ParseDuration 的基础上支持天（如 "1d5h20m"）和纯数字（纳秒），格式无效时返回错误
 func ParseDuration(d string) (time.Duration, error) {
 	d = strings.TrimSpace(d)
 	dr, err := time.ParseDuration(d)
 	if err == nil {
 		return dr, nil
 	}
-	if strings.Contains(d, "d") {
-		index := strings.Index(d, "d")
-
-		hour, _ := strconv.Atoi(d[:index])
-		dr = time.Hour * 24 * time.Duration(hour)
-		ndr, err := time.ParseDuration(d[index+1:])
+	if index := strings.Index(d, "d"); index >= 0 {
+		days, err := strconv.Atoi(d[:index])
 		if err != nil {
+			return 0, fmt.Errorf("无效的时长 %q: 天数必须是整数", d)
+		}
+		dr = time.Hour * 24 * time.Duration(days)
+		rest := d[index+1:]
+		if rest == "" {
 			return dr, nil
 		}
+		ndr, err := time.ParseDuration(rest)
+		if err != nil || ndr < 0 || strings.HasPrefix(rest, "+") {
+			return 0, fmt.Errorf("无效的时长 %q", d)
+		}
+		// "-1d2h" 整体为负
+		if strings.HasPrefix(d, "-") {
+			return dr - ndr, nil
+		}
 		return dr + ndr, nil
 	}
 
@@ -34,6 +34,38 @@ func TestParseDuration(t *testing.T) {
 			want:    24 * time.Hour,
 			wantErr: false,
 		},
+		{
+			name:    "-1d2h",
+			args:    args{"-1d2h"},
+			want:    -26 * time.Hour,
+			wantErr: false,
+		},
+		{
+			name:    "1500",
+			args:    args{"1500"},
+			want:    1500,
+			wantErr: false,
+		},
+		{
+			name:    "bad",
+			args:    args{"bad"},
+			wantErr: true,
+		},
+		{
+			name:    "xd",
+			args:    args{"xd"},
+			wantErr: true,
+		},
+		{
+			name:    "1dfoo",
+			args:    args{"1dfoo"},
+			wantErr: true,
+		},
+		{
+			name:    "1d-2h",
+			args:    args{"1d-2h"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {